@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd represents the config command
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage connection settings and credentials",
+	Long: `Manage the connection settings docli uses to reach your platforms.
+
+Settings are grouped in named profiles stored in a user configuration file
+outside of your project (config.json in the docli directory of your user
+configuration directory, or the file named by DOCLI_CONFIG). Credentials are
+never written to .docs/spec.json.
+
+Available keys:
+  confluence.url        Confluence REST API base URL (DOCLI_CONFLUENCE_URL)
+  confluence.username   Confluence username (DOCLI_CONFLUENCE_USERNAME)
+  confluence.api_token  Confluence API token (DOCLI_CONFLUENCE_API_TOKEN)
+  confluence.space      Confluence space key (DOCLI_CONFLUENCE_SPACE)
+  llm.url               OpenAI compatible API base URL (DOCLI_LLM_URL)
+  llm.api_key           LLM API key (DOCLI_LLM_API_KEY)
+  llm.model             LLM model name (DOCLI_LLM_MODEL)
+  llm.context_tokens    context window of the model in tokens (DOCLI_LLM_CONTEXT_TOKENS)
+
+The environment variables take precedence over the stored values, which makes
+them a good fit for CI. The profile is chosen with --profile, then
+DOCLI_PROFILE, then the profile selected with 'docli config use'.`,
+}
+
+func init() {
+	RootCmd.AddCommand(ConfigCmd)
+	ConfigCmd.PersistentFlags().String("profile", "", "profile to use instead of the current one")
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// ConfigTestCmd represents the config test command
+var ConfigTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Check the connection to the configured server",
+	Long: `Connect to the configured Confluence server, authenticate and check that the
+configured space can be accessed. Environment overrides are taken into account.
+
+Example:
+  docli config test
+  docli config test --profile staging`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		return runConfigTest(profile)
+	},
+}
+
+func runConfigTest(profile string) error {
+	configRepo := config.NewConfigRepo()
+	testCmd := config.NewTestConfigCommand(configRepo, profile)
+	return testCmd.Run()
+}
+
+func init() {
+	ConfigCmd.AddCommand(ConfigTestCmd)
+}
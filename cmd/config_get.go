@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// ConfigGetCmd represents the config get command
+var ConfigGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value",
+	Long: `Print a configuration value stored in the current profile, or in the profile
+given with --profile. Secrets are masked unless --show-secret is given.
+
+Example:
+  docli config get confluence.url
+  docli config get confluence.api_token --show-secret`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		showSecret, _ := cmd.Flags().GetBool("show-secret")
+		return runConfigGet(profile, args[0], showSecret)
+	},
+}
+
+func runConfigGet(profile, key string, showSecret bool) error {
+	configRepo := config.NewConfigRepo()
+	getCmd := config.NewGetConfigCommand(configRepo, profile, key, showSecret)
+	return getCmd.Run()
+}
+
+func init() {
+	ConfigCmd.AddCommand(ConfigGetCmd)
+	ConfigGetCmd.Flags().Bool("show-secret", false, "print secret values unmasked")
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// ConfigListCmd represents the config list command
+var ConfigListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all profiles and their settings",
+	Long: `List every profile with its settings, marking the current profile with '*'.
+Secrets are masked. Settings overridden by DOCLI_CONFLUENCE_* environment
+variables are listed separately.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigList()
+	},
+}
+
+func runConfigList() error {
+	configRepo := config.NewConfigRepo()
+	listCmd := config.NewListConfigCommand(configRepo)
+	return listCmd.Run()
+}
+
+func init() {
+	ConfigCmd.AddCommand(ConfigListCmd)
+}
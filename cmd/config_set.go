@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// ConfigSetCmd represents the config set command
+var ConfigSetCmd = &cobra.Command{
+	Use:   "set <key> [value]",
+	Short: "Set a configuration value",
+	Long: `Set a configuration value in the current profile, or in the profile given
+with --profile. The profile is created when it does not exist yet.
+
+When the value is left out it is read from standard input, which keeps API
+tokens out of your shell history.
+
+Example:
+  docli config set confluence.url https://example.atlassian.net/wiki/rest/api
+  docli config set confluence.api_token
+  docli config set confluence.space DOCS --profile staging`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, _ := cmd.Flags().GetString("profile")
+		if len(args) == 2 {
+			return runConfigSet(profile, args[0], args[1])
+		}
+
+		logger.Prompt("Enter value for %s: ", args[0])
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		return runConfigSet(profile, args[0], strings.TrimSpace(input))
+	},
+}
+
+func runConfigSet(profile, key, value string) error {
+	configRepo := config.NewConfigRepo()
+	setCmd := config.NewSetConfigCommand(configRepo, profile, key, value)
+	return setCmd.Run()
+}
+
+func init() {
+	ConfigCmd.AddCommand(ConfigSetCmd)
+}
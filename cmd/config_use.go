@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// ConfigUseCmd represents the config use command
+var ConfigUseCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "Select the current profile",
+	Long: `Select the profile used by every command that does not get --profile.
+
+Example:
+  docli config use staging`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigUse(args[0])
+	},
+}
+
+func runConfigUse(profile string) error {
+	configRepo := config.NewConfigRepo()
+	useCmd := config.NewUseConfigCommand(configRepo, profile)
+	return useCmd.Run()
+}
+
+func init() {
+	ConfigCmd.AddCommand(ConfigUseCmd)
+}
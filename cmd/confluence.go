@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/Hasankanso/docli/internal/confluence"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/spf13/cobra"
+)
+
+// addConfluenceFlags registers the connection flags shared by the Confluence commands
+func addConfluenceFlags(cmd *cobra.Command) {
+	cmd.Flags().String("url", "", "Confluence REST API base URL")
+	cmd.Flags().String("username", "", "Confluence username")
+	cmd.Flags().String("space", "", "Confluence space key")
+	cmd.Flags().String("profile", "", "config profile to take the connection settings from")
+}
+
+// newConfluenceClient builds a client from the connection flags, falling back
+// to the DOCLI_CONFLUENCE_* environment variables and then to the config
+// profile. Incomplete settings are a validation error.
+func newConfluenceClient(cmd *cobra.Command) (*confluence.ConfluenceClient, string, error) {
+	profile, _ := cmd.Flags().GetString("profile")
+	settings, err := config.NewConfigRepo().Confluence(profile)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	baseURL, _ := cmd.Flags().GetString("url")
+	username, _ := cmd.Flags().GetString("username")
+	spaceKey, _ := cmd.Flags().GetString("space")
+
+	baseURL = flagOrSetting(baseURL, settings.URL)
+	username = flagOrSetting(username, settings.Username)
+	spaceKey = flagOrSetting(spaceKey, settings.Space)
+
+	if baseURL == "" || spaceKey == "" {
+		return nil, "", errs.Validation("missing Confluence connection settings, please provide --url and --space " +
+			"or store them with 'docli config set confluence.url <url>' and 'docli config set confluence.space <key>'")
+	}
+
+	client, err := confluence.NewConfluenceClient(baseURL, username, settings.APIToken)
+	if err != nil {
+		return nil, "", errs.Validation("failed to create Confluence client: %w", err)
+	}
+	return client, spaceKey, nil
+}
+
+func flagOrSetting(value, setting string) string {
+	if value != "" {
+		return value
+	}
+	return setting
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/Hasankanso/docli/internal/docmeta"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/hints"
 	"github.com/Hasankanso/docli/internal/logger"
 	"github.com/Hasankanso/docli/internal/spec"
 	"github.com/spf13/cobra"
@@ -13,34 +17,133 @@ import (
 
 // createDocmetaCmd represents the create docmeta command
 var createDocmetaCmd = &cobra.Command{
-	Use:   "docmeta",
+	Use:   "docmeta [-]",
 	Short: "Create a new document metadata entry",
 	Long: `Create a new document metadata entry and add it to your spec.md file.
 This command will guide you through an interactive process to define a new
-document with its name, description, and file hints.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		runCreateDocmeta()
+document with its name, description, and file hints.
+
+The prompts are skipped when --name is given, so that entries can be created
+from scripts. --from-file reads one or many entries as JSON or YAML, using
+the field names of spec.json; pass - or --from-file - to read from stdin.
+Targets are ignored, they are recorded by syncing.
+
+A file hint is a file, a folder whose files are all included, or a glob like
+cmd/**/*.go where ** matches any number of folders. A hint starting with !
+leaves out files matched by the hints before it, e.g. !**/*_test.go. Folders
+and globs skip the files ignored by .gitignore, hidden files like .env,
+binary files and files larger than 256 KiB. Hints that match nothing in the working tree are reported, and
+the interactive prompts ask whether to keep them or enter them again.
+
+Example:
+  docli create docmeta --name "API Reference" --description "REST endpoints" --hint api/ --hint openapi.yaml
+  docli create docmeta --from-file docs.yaml
+  cat docs.json | docli create docmeta -`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromFile, _ := cmd.Flags().GetString("from-file")
+		if len(args) == 1 {
+			if args[0] != "-" || fromFile != "" {
+				return errs.Usage("unexpected argument '%s', use --from-file to read entries from a file", args[0])
+			}
+			fromFile = "-"
+		}
+		if fromFile != "" {
+			return runCreateDocmetaFromFile(fromFile)
+		}
+
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			if localFlagsChanged(cmd) {
+				return errs.Usage("--name is required when creating document metadata from flags")
+			}
+			return runCreateDocmeta()
+		}
+		return runCreateDocmetaFromFlags(cmd, name)
 	},
 }
 
-func runCreateDocmeta() {
+func runCreateDocmeta() error {
 	reader := bufio.NewReader(os.Stdin)
 	newDocMeta := CollectSingleDocumentDetails(reader)
 	if newDocMeta == nil {
 		logger.Info("Document creation cancelled")
-		return
+		return nil
+	}
+	specRepo := spec.NewSpecRepo()
+	createCmd := docmeta.NewCreateDocMetaCommand(specRepo, newDocMeta)
+
+	return createCmd.Run()
+}
+
+func runCreateDocmetaFromFlags(cmd *cobra.Command, name string) error {
+	description, _ := cmd.Flags().GetString("description")
+	fileHints, _ := cmd.Flags().GetStringArray("hint")
+	id, _ := cmd.Flags().GetString("id")
+	parent, _ := cmd.Flags().GetString("parent")
+	labels, _ := cmd.Flags().GetStringArray("label")
+	properties, _ := cmd.Flags().GetStringArray("property")
+
+	newDocMeta := spec.NewDocMetaData(name, description, fileHints)
+	if id != "" {
+		newDocMeta.ID = id
 	}
+	newDocMeta.Parent = parent
+	newDocMeta.AddLabels(labels...)
+	for _, property := range properties {
+		key, value, err := spec.ParseProperty(property)
+		if err != nil {
+			return errs.Wrap(errs.KindUsage, err)
+		}
+		if newDocMeta.Properties == nil {
+			newDocMeta.Properties = map[string]string{}
+		}
+		newDocMeta.Properties[key] = value
+	}
+
+	warnUnresolvedHints(newDocMeta.Name, newDocMeta.FileHints)
 	specRepo := spec.NewSpecRepo()
 	createCmd := docmeta.NewCreateDocMetaCommand(specRepo, newDocMeta)
+	return createCmd.Run()
+}
+
+func runCreateDocmetaFromFile(path string) error {
+	var content []byte
+	var err error
+	if path == "-" {
+		path = "stdin"
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading document metadata: %w", err)
+	}
+
+	docs, err := spec.ParseDocMeta(content)
+	if err != nil {
+		return errs.Validation("error reading document metadata from %s: %w", path, err)
+	}
+	if len(docs) == 0 {
+		logger.Info("No document metadata entries found in %s", path)
+		return nil
+	}
 
-	createCmd.Run()
+	newDocMeta := make([]*spec.DocMetaData, 0, len(docs))
+	for i := range docs {
+		warnUnresolvedHints(docs[i].Name, docs[i].FileHints)
+		newDocMeta = append(newDocMeta, &docs[i])
+	}
+	specRepo := spec.NewSpecRepo()
+	createCmd := docmeta.NewCreateDocMetaCommand(specRepo, newDocMeta...)
+	return createCmd.Run()
 }
 
 func CollectSingleDocumentDetails(reader *bufio.Reader) *spec.DocMetaData {
 	logger.Info("\n--- New Document Configuration ---")
 
 	// Ask for document name
-	logger.Info("Enter document title: ")
+	logger.Prompt("Enter document title: ")
 	input, _ := reader.ReadString('\n')
 	docName := strings.TrimSpace(input)
 
@@ -50,7 +153,7 @@ func CollectSingleDocumentDetails(reader *bufio.Reader) *spec.DocMetaData {
 	}
 
 	// Ask for document description
-	logger.Info("Enter description for '%s': ", docName)
+	logger.Prompt("Enter description for '%s': ", docName)
 	input, _ = reader.ReadString('\n')
 	docDescription := strings.TrimSpace(input)
 
@@ -61,7 +164,7 @@ func CollectSingleDocumentDetails(reader *bufio.Reader) *spec.DocMetaData {
 	var fileHints []string
 	hintNum := 1
 	for {
-		logger.Info("  File/Folder %d (or press Enter to finish): ", hintNum)
+		logger.Prompt("  File/Folder %d (or press Enter to finish): ", hintNum)
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
 
@@ -69,7 +172,11 @@ func CollectSingleDocumentDetails(reader *bufio.Reader) *spec.DocMetaData {
 			break
 		}
 
-		fileHints = append(fileHints, input)
+		hint := checkHint(reader, input)
+		if hint == "" {
+			continue
+		}
+		fileHints = append(fileHints, hint)
 		hintNum++
 	}
 
@@ -79,5 +186,86 @@ func CollectSingleDocumentDetails(reader *bufio.Reader) *spec.DocMetaData {
 		logger.Info("Added %d file/folder hint(s) for '%s'", len(fileHints), docName)
 	}
 
-	return spec.NewDocMetaData(docName, docDescription, fileHints)
+	// Ask for the parent page
+	logger.Prompt("\nEnter the parent of '%s', a document ID or a Confluence page ID (or press Enter for none): ", docName)
+	input, _ = reader.ReadString('\n')
+
+	docMeta := spec.NewDocMetaData(docName, docDescription, fileHints)
+	docMeta.Parent = strings.TrimSpace(input)
+
+	// Ask for labels and page properties
+	logger.Prompt("\nEnter labels for '%s', separated by commas (or press Enter for none): ", docName)
+	input, _ = reader.ReadString('\n')
+	docMeta.AddLabels(strings.Split(input, ",")...)
+
+	logger.Info("\nEnter page properties for '%s' as key=value. Press Enter on an empty line when done.", docName)
+	for {
+		logger.Prompt("  Property (or press Enter to finish): ")
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		if input == "" {
+			break
+		}
+
+		key, value, err := spec.ParseProperty(input)
+		if err != nil {
+			logger.Warning("%v", err)
+			continue
+		}
+		if docMeta.Properties == nil {
+			docMeta.Properties = map[string]string{}
+		}
+		docMeta.Properties[key] = value
+	}
+
+	return docMeta
+}
+
+// checkHint warns when a hint matches nothing in the working tree and asks
+// whether to keep it or enter it again. It returns the hint to add, empty to
+// drop it.
+func checkHint(reader *bufio.Reader, hint string) string {
+	for hint != "" {
+		match, err := hints.Check(hint)
+		if err != nil {
+			logger.Warning("Could not check '%s': %v", hint, err)
+			return hint
+		}
+		issue := match.Issue()
+		if issue == "" {
+			return hint
+		}
+		logger.Warning("'%s' %s", hint, issue)
+		if askYesNoDefault(reader, false, "  Keep it anyway? (y/N): ") {
+			return hint
+		}
+		logger.Prompt("  Enter it again (or press Enter to drop it): ")
+		input, _ := reader.ReadString('\n')
+		hint = strings.TrimSpace(input)
+	}
+	return ""
+}
+
+// warnUnresolvedHints warns about the hints of a document that match nothing
+func warnUnresolvedHints(name string, fileHints []string) {
+	set, err := hints.Resolve(fileHints)
+	if err != nil {
+		logger.Warning("Could not check the file hints of '%s': %v", name, err)
+		return
+	}
+	for _, match := range set.Unresolved() {
+		logger.Warning("File hint '%s' of '%s' %s", match.Hint, name, match.Issue())
+	}
+}
+
+func init() {
+	createDocmetaCmd.Flags().String("name", "", "document title, skips the interactive prompts")
+	createDocmetaCmd.Flags().String("description", "", "document description")
+	createDocmetaCmd.Flags().StringArray("hint", nil, "file, folder or glob with relevant content, !<hint> to leave files out, can be repeated")
+	createDocmetaCmd.Flags().String("id", "", "document ID, generated when not given")
+	createDocmetaCmd.Flags().String("parent", "", "parent document ID or Confluence page ID")
+	createDocmetaCmd.Flags().StringArray("label", nil, "Confluence label, can be repeated")
+	createDocmetaCmd.Flags().StringArray("property", nil, "page property as key=value, can be repeated")
+	createDocmetaCmd.Flags().String("from-file", "", "read one or many entries as JSON or YAML, - for stdin")
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/Hasankanso/docli/internal/confluence"
 	"github.com/Hasankanso/docli/internal/docmeta"
 	"github.com/Hasankanso/docli/internal/spec"
 	"github.com/spf13/cobra"
@@ -13,23 +14,45 @@ var DeleteDocmetaCmd = &cobra.Command{
 	Long: `Delete a document metadata entry from your spec.md file by providing the document id.
 The id should be provided in quotes if it contains spaces.
 
+By default only the entry is removed. Use --remote to also delete the
+Confluence page it is synced with, and --local to delete its generated
+markdown file.
+
+A document that is the parent of other documents cannot be deleted. Delete its
+children first, or give them another parent with 'docli update docmeta'.
+
 Example:
   docli delete docmeta "API Documentation"
-  docli delete docmeta README`,
+  docli delete docmeta README
+  docli delete docmeta README --remote --local`,
 	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		id := args[0]
-		runDeleteDocmeta(id)
+		remote, _ := cmd.Flags().GetBool("remote")
+		local, _ := cmd.Flags().GetBool("local")
+		return runDeleteDocmeta(cmd, id, remote, local)
 	},
 }
 
-func runDeleteDocmeta(id string) {
+func runDeleteDocmeta(cmd *cobra.Command, id string, remote, local bool) error {
+	var client *confluence.ConfluenceClient
+	if remote {
+		var err error
+		client, _, err = newConfluenceClient(cmd)
+		if err != nil {
+			return err
+		}
+	}
+
 	specRepo := spec.NewSpecRepo()
-	deleteCmd := docmeta.NewDeleteDocMetaCommand(specRepo, id)
-	deleteCmd.Run()
+	deleteCmd := docmeta.NewDeleteDocMetaCommand(specRepo, id, client, local)
+	return deleteCmd.Run()
 }
 
 func init() {
 	RootCmd.AddCommand(DeleteCmd)
 	DeleteCmd.AddCommand(DeleteDocmetaCmd)
+	addConfluenceFlags(DeleteDocmetaCmd)
+	DeleteDocmetaCmd.Flags().Bool("remote", false, "also delete the Confluence page of the document")
+	DeleteDocmetaCmd.Flags().Bool("local", false, "also delete the generated markdown file of the document")
 }
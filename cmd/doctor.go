@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/Hasankanso/docli/internal/doctor"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// DoctorCmd represents the doctor command
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the documentation project for problems",
+	Long: `Audit the whole documentation project and list every problem found:
+
+  spec        spec.md does not match spec.json
+  docmeta     invalid entries, duplicate IDs or names, documents sharing a
+              file, parents that are neither a document nor a page ID
+  hints       file hints that match nothing or are invalid, documents
+              without hints
+  docs        documents that were never generated
+  prompts     prompt files missing from .github/prompts
+  confluence  missing or unreachable Confluence settings, when Confluence
+              is a platform of the project
+
+Documents without hints, documents not generated yet and prompts that cannot
+be compared with their source are warnings, every other problem is an error. doctor exits with code 4 when it finds errors, or
+warnings with --strict, which makes it a fit for CI.
+
+Example:
+  docli doctor
+  docli doctor --offline --strict
+  docli doctor --fix
+  docli doctor --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor(cmd)
+	},
+}
+
+func runDoctor(cmd *cobra.Command) error {
+	profile, _ := cmd.Flags().GetString("profile")
+	offline, _ := cmd.Flags().GetBool("offline")
+	strict, _ := cmd.Flags().GetBool("strict")
+	fix, _ := cmd.Flags().GetBool("fix")
+
+	// A broken prompt source should not hide the other problems
+	source, err := promptsSource(cmd)
+	if err != nil {
+		logger.Warning("Skipping the prompt checks: %v", err)
+		source = nil
+	}
+
+	doctorCmd := doctor.NewDoctorCommand(spec.NewSpecRepo(), config.NewConfigRepo(), profile, source, offline, strict, fix, outputFormat(cmd))
+	return doctorCmd.Run()
+}
+
+func init() {
+	RootCmd.AddCommand(DoctorCmd)
+	DoctorCmd.Flags().String("profile", "", "config profile to take the Confluence settings from")
+	DoctorCmd.Flags().Bool("offline", false, "do not connect to Confluence")
+	DoctorCmd.Flags().String("prompts-source", "", "where to compare the prompt files with (default: the source they were installed from)")
+	DoctorCmd.Flags().Bool("strict", false, "fail on warnings too")
+	DoctorCmd.Flags().Bool("fix", false, "regenerate spec.md when it does not match spec.json")
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// EditCmd represents the edit command
+var EditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit resources in your editor",
+	Long: `Edit various types of resources of your documentation project in $EDITOR.
+
+Available resource types:
+  docmeta - Edit a document metadata entry by id as YAML
+
+Use the appropriate subcommand to edit the specific type of resource you want to change.`,
+}
+
+func init() {
+	RootCmd.AddCommand(EditCmd)
+}
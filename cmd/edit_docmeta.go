@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	"github.com/Hasankanso/docli/internal/docmeta"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// EditDocmetaCmd represents the edit docmeta command
+var EditDocmetaCmd = &cobra.Command{
+	Use:   "docmeta <id>",
+	Short: "Edit a document metadata entry in your editor",
+	Long: `Open a document metadata entry as YAML in $VISUAL or $EDITOR (vi when
+neither is set). The entry is validated when the editor is closed, an
+invalid entry can be edited again or discarded. Closing the editor without
+changes leaves the entry alone.
+
+Example:
+  docli edit docmeta abc123
+  EDITOR="code --wait" docli edit docmeta abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runEditDocmeta(args[0])
+	},
+}
+
+func runEditDocmeta(id string) error {
+	reader := bufio.NewReader(os.Stdin)
+	edit := func(content []byte, problem error) ([]byte, error) {
+		return editInEditor(id, content, problem)
+	}
+	retry := func(problem error) bool {
+		return askYesNoDefault(reader, true, "Edit again? (Y/n): ")
+	}
+
+	specRepo := spec.NewSpecRepo()
+	editCmd := docmeta.NewEditDocMetaCommand(specRepo, id, edit, retry)
+	return editCmd.Run()
+}
+
+// editInEditor writes content to a temporary file, opens it in the user's
+// editor and returns the saved file
+func editInEditor(id string, content []byte, problem error) ([]byte, error) {
+	// IDs may contain path separators and other characters that do not
+	// belong in a file name
+	name := strings.Map(func(char rune) rune {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) || char == '-' || char == '_' {
+			return char
+		}
+		return '_'
+	}, id)
+	file, err := os.CreateTemp("", "docmeta-"+name+"-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	// Show why the last edit was rejected as a comment on top, replacing the
+	// previous reason
+	lines := strings.SplitAfter(string(content), "\n")
+	for len(lines) > 0 && strings.HasPrefix(lines[0], "#") {
+		lines = lines[1:]
+	}
+	content = []byte(strings.Join(lines, ""))
+	if problem != nil {
+		comment := "# Error: " + strings.ReplaceAll(problem.Error(), "\n", "\n#   ") + "\n"
+		content = append([]byte(comment), content...)
+	}
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may come with arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	command := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	err = command.Run()
+	if err != nil {
+		return nil, fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+	return os.ReadFile(file.Name())
+}
+
+func init() {
+	EditCmd.AddCommand(EditDocmetaCmd)
+}
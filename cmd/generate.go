@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/generate"
+	"github.com/Hasankanso/docli/internal/llm"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// defaultContextTokens fits the models most local servers run
+const defaultContextTokens = 32000
+
+// GenerateCmd represents the generate command
+var GenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Write the documents in .docs/ with an LLM",
+	Long: `Generate the markdown of every document, or only the one given with --doc,
+by calling an LLM over an OpenAI compatible chat completions API.
+
+The request is the updateDoc prompt rendered for the document (see
+'docli prompt render --help'), followed by the files its hints resolve to.
+Files that do not fit in the context window are left out with a warning. The
+reply is written to the document's file in .docs/, replacing it.
+
+The API is configured in the config profile, or with the DOCLI_LLM_*
+environment variables:
+
+  docli config set llm.url http://localhost:11434/v1
+  docli config set llm.model llama3.1
+  docli config set llm.api_key
+  docli config set llm.context_tokens 128000
+
+llm.url defaults to the OpenAI API and llm.context_tokens to 32000. Rate
+limits, server errors and network errors are retried.
+
+Example:
+  docli generate
+  docli generate --doc abc123
+  docli generate --doc abc123 --dry-run
+  docli generate --model gpt-4o --max-tokens 8000`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGenerate(cmd)
+	},
+}
+
+func runGenerate(cmd *cobra.Command) error {
+	id, _ := cmd.Flags().GetString("doc")
+	prompt, _ := cmd.Flags().GetString("prompt")
+	profile, _ := cmd.Flags().GetString("profile")
+	baseURL, _ := cmd.Flags().GetString("url")
+	model, _ := cmd.Flags().GetString("model")
+	contextTokens, _ := cmd.Flags().GetInt("context-tokens")
+	maxTokens, _ := cmd.Flags().GetInt("max-tokens")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	settings, err := config.NewConfigRepo().LLM(profile)
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %w", err)
+	}
+	baseURL = flagOrSetting(baseURL, settings.URL)
+	model = flagOrSetting(model, settings.Model)
+	if model == "" && !dryRun {
+		return errs.Validation("no model configured, please provide --model or store it with 'docli config set llm.model <model>'")
+	}
+	if contextTokens == 0 && settings.ContextTokens != "" {
+		contextTokens, err = strconv.Atoi(settings.ContextTokens)
+		if err != nil {
+			return errs.Validation("llm.context_tokens must be a number, got '%s'", settings.ContextTokens)
+		}
+	}
+	if contextTokens == 0 {
+		contextTokens = defaultContextTokens
+	}
+	if maxTokens <= 0 || maxTokens >= contextTokens {
+		return errs.Usage("--max-tokens must be positive and smaller than the context window of %d tokens", contextTokens)
+	}
+
+	source, err := promptsSource(cmd)
+	if err != nil {
+		return err
+	}
+	client := llm.NewClient(baseURL, settings.APIKey, model)
+	generateCmd := generate.NewGenerateCommand(spec.NewSpecRepo(), id, prompt, source, client, contextTokens, maxTokens, dryRun)
+	return generateCmd.Run()
+}
+
+func init() {
+	RootCmd.AddCommand(GenerateCmd)
+	GenerateCmd.Flags().String("doc", "", "ID of the document to generate, all documents when empty")
+	GenerateCmd.Flags().String("prompt", generate.DefaultPrompt, "prompt name or file to render the request from")
+	GenerateCmd.Flags().String("prompts-source", "", "where to take the prompt from when it is not in .github/prompts")
+	GenerateCmd.Flags().String("profile", "", "config profile to take the LLM settings from")
+	GenerateCmd.Flags().String("url", "", "base URL of the OpenAI compatible API")
+	GenerateCmd.Flags().String("model", "", "model to generate with")
+	GenerateCmd.Flags().Int("context-tokens", 0, "context window of the model (default: llm.context_tokens or 32000)")
+	GenerateCmd.Flags().Int("max-tokens", 4096, "tokens kept for the reply")
+	GenerateCmd.Flags().Bool("dry-run", false, "print the requests instead of sending them")
+}
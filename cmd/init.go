@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/prompts"
 	"github.com/Hasankanso/docli/internal/spec"
 	"github.com/spf13/cobra"
 )
@@ -17,13 +18,37 @@ var initCmd = &cobra.Command{
 	Short: "Initialize basic documentation project structure",
 	Long: `Initialize your documentation project by setting up the basic configuration
 structure. This will copy prompt files and create the initial spec.md file
-with platform configuration. Use 'docli create docmeta' to add document metadata.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		runInit()
+with platform configuration. Use 'docli create docmeta' to add document metadata.
+
+The prompt files ship with docli, so init works offline. Prompt sources
+declared in the user config take precedence over them, see
+'docli prompts --help'. --prompts-source copies them from elsewhere instead:
+'github' fetches the latest ones from the docli repository, git+<repo> clones
+a git repository, a directory copies every *.prompt.md file in it, and an
+http(s) base URL fetches the prompts listed in <url>/index.json.
+
+Example:
+  docli init
+  docli init --prompts-source github
+  docli init --prompts-source ./shared/prompts
+  docli init --prompts-source git+https://github.com/acme/docli-prompts.git#v2
+  docli init --prompts-source https://intranet.example.com/docli/prompts`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		promptsSource, _ := cmd.Flags().GetString("prompts-source")
+		return runInit(promptsSource)
 	},
 }
 
-func runInit() {
+func runInit(promptsSource string) error {
+	registries, err := promptRegistries()
+	if err != nil {
+		return err
+	}
+	source, err := prompts.NewSource(promptsSource, registries)
+	if err != nil {
+		return err
+	}
+
 	logger.Info("Welcome to docli initialization!")
 
 	specRepo := spec.NewSpecRepo()
@@ -33,15 +58,15 @@ func runInit() {
 	platforms := askForPlatforms(reader)
 
 	// Step 4: Initialize spec repository and save initial config
-	spec.NewInitSpecCommand(specRepo, platforms).Run()
+	return spec.NewInitSpecCommand(specRepo, platforms, source).Run()
 }
 
 func askForPlatforms(reader *bufio.Reader) []string {
-	logger.Info("Which platforms do you want to sync your documentation to?")
-	logger.Info("1. Confluence")
-	logger.Info("2. README")
-	logger.Info("\nYou can select multiple platforms by entering numbers separated by commas (e.g., 1,2)")
-	logger.Info("Select platforms (1): ")
+	logger.Prompt("Which platforms do you want to sync your documentation to?")
+	logger.Prompt("1. Confluence")
+	logger.Prompt("2. README")
+	logger.Prompt("\nYou can select multiple platforms by entering numbers separated by commas (e.g., 1,2)")
+	logger.Prompt("Select platforms (1): ")
 
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
@@ -82,4 +107,5 @@ func askForPlatforms(reader *bufio.Reader) []string {
 
 func init() {
 	RootCmd.AddCommand(initCmd)
+	initCmd.Flags().String("prompts-source", prompts.SourceDefault, "where to copy the prompt files from, see 'docli prompts --help'")
 }
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"github.com/Hasankanso/docli/internal/docmeta"
-	"github.com/Hasankanso/docli/internal/logger"
 	"github.com/Hasankanso/docli/internal/spec"
 	"github.com/spf13/cobra"
 )
@@ -12,20 +11,21 @@ var ListDocmetaCmd = &cobra.Command{
 	Use:   "docmeta",
 	Short: "List all document metadata entries",
 	Long: `List all document metadata entries from your spec.md file.
-This command displays all configured documents with their names and descriptions.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		runListDocmeta()
+This command displays all configured documents as a table, or as JSON, YAML
+or CSV with --output for use in scripts.
+
+Example:
+  docli list docmeta
+  docli list docmeta --output json | jq -r '.[].id'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runListDocmeta(outputFormat(cmd))
 	},
 }
 
-func runListDocmeta() {
+func runListDocmeta(output string) error {
 	specRepo := spec.NewSpecRepo()
-	if !specRepo.SpecExists() {
-		logger.Error("No spec.md file found. Please run 'docli init' to initialize your project")
-		return
-	}
-	ListDocMetaCmd := docmeta.NewListDocMetaCommand(specRepo)
-	ListDocMetaCmd.Run()
+	ListDocMetaCmd := docmeta.NewListDocMetaCommand(specRepo, output)
+	return ListDocMetaCmd.Run()
 }
 
 func init() {
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Hasankanso/docli/internal/confluence"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/plan"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// PlanCmd represents the plan command
+var PlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show what a sync would change on every platform",
+	Long: `Work out what 'docli sync' would do on every configured platform without
+changing anything. Each document is listed with the action a sync would take
+(create, update, pull, merge, move or none) and a diff of the body it would change.
+Documents a sync would refuse to touch are listed as outdated, conflict or
+missing. Pages docli created that no longer belong to a document are listed
+as delete, with the body 'docli prune' would remove.
+
+The plan is printed as text, or as JSON or YAML with --output json|yaml so
+CI can post it as a pull request comment.
+
+Connection settings are the same as for 'docli sync confluence'.
+
+Example:
+  docli plan --space DOCS
+  docli plan --space DOCS --output json > plan.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPlan(cmd, outputFormat(cmd))
+	},
+}
+
+func runPlan(cmd *cobra.Command, format string) error {
+	specRepo := spec.NewSpecRepo()
+	if !specRepo.SpecExists() {
+		return errs.SpecNotFound()
+	}
+
+	docSpec, err := specRepo.GetSpec()
+	if err != nil {
+		return fmt.Errorf("error reading documentation configuration: %w", err)
+	}
+
+	plans := []*plan.Plan{}
+	for _, platform := range docSpec.Platforms {
+		switch platform {
+		case "confluence":
+			client, spaceKey, err := newConfluenceClient(cmd)
+			if err != nil {
+				return err
+			}
+			syncPlan, err := confluence.NewSyncConfluenceCommand(specRepo, client, spaceKey, confluence.ResolveNone, "").Plan()
+			if err != nil {
+				return fmt.Errorf("failed to plan Confluence sync: %w", err)
+			}
+			plans = append(plans, syncPlan)
+		default:
+			logger.Warning("Syncing to %s is not supported yet, skipping it", platform)
+		}
+	}
+
+	err = plan.Write(os.Stdout, format, plans)
+	if err != nil {
+		return errs.Wrap(errs.KindUsage, fmt.Errorf("failed to print the plan: %w", err))
+	}
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(PlanCmd)
+	addConfluenceFlags(PlanCmd)
+}
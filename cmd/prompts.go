@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// PromptsCmd represents the prompts command
+var PromptsCmd = &cobra.Command{
+	Use:     "prompts",
+	Aliases: []string{"prompt"},
+	Short:   "Keep the prompt files in .github/prompts up to date",
+	Long: `Compare the prompt files in .github/prompts with the ones docli ships, and
+bring them up to date without losing your own changes.
+
+The version of every installed prompt is recorded in
+.github/prompts/prompts.lock.json, along with a copy of it in
+.github/prompts/.docli. Commit both, they tell your changes apart from the
+changes made upstream.
+
+Prompts are compared with the source they were installed from, unless
+--prompts-source is given. It accepts:
+
+  default      the prompt sources declared in spec.json, then those of the
+               user config, then the prompts shipped with docli
+  <name>       a prompt source declared with 'docli prompts add'
+  embedded     the prompts shipped with docli
+  github       the latest prompts of the docli repository
+  git+<repo>   a git repository, optionally with #<branch or tag>
+  <url>        an http(s) base URL listing its prompts in index.json
+  <directory>  a local directory
+
+When several declared sources have a prompt with the same name, the one
+declared first wins, and project sources win over user sources.`,
+}
+
+// declaredRegistries returns the prompt sources declared in spec.json and
+// in the user config
+func declaredRegistries() ([]prompts.Registry, []prompts.Registry, error) {
+	var project []prompts.Registry
+	specRepo := spec.NewSpecRepo()
+	if specRepo.SpecExists() {
+		docSpec, err := specRepo.GetSpec()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading documentation configuration: %w", err)
+		}
+		project = docSpec.PromptSources
+	}
+	userConfig, err := config.NewConfigRepo().Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading configuration: %w", err)
+	}
+	return project, userConfig.PromptSources, nil
+}
+
+// promptRegistries returns the declared prompt sources by precedence
+func promptRegistries() ([]prompts.Registry, error) {
+	project, user, err := declaredRegistries()
+	if err != nil {
+		return nil, err
+	}
+	return prompts.Precedence(project, user), nil
+}
+
+// promptsSource resolves --prompts-source, falling back to the source the
+// prompts were installed from
+func promptsSource(cmd *cobra.Command) (prompts.Source, error) {
+	value, _ := cmd.Flags().GetString("prompts-source")
+	registries, err := promptRegistries()
+	if err != nil {
+		return nil, err
+	}
+	return prompts.ResolveSource(value, registries)
+}
+
+func init() {
+	RootCmd.AddCommand(PromptsCmd)
+	PromptsCmd.PersistentFlags().String("prompts-source", "", "where to take the prompt files from (default: the source they were installed from)")
+}
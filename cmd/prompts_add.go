@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+var PromptsAddCmd = &cobra.Command{
+	Use:   "add <source>",
+	Short: "Declare a prompt source and install its prompts",
+	Long: `Declare a prompt source, for example your organisation's house-style
+prompts, and install the prompts it provides. The source is a local
+directory, a git repository (git+<repo>, optionally with #<branch or tag>)
+or an http(s) base URL listing its prompts in index.json.
+
+The source is declared in spec.json for the whole project, or in the user
+config with --user for every project. It is named after the directory or
+repository unless --name is given, and the name can be passed to
+--prompts-source. Prompts the source shares a name with are updated to its
+version, keeping your own changes, unless a source with precedence has them
+too (see 'docli prompts --help').
+
+Example:
+  docli prompts add ../house-prompts
+  docli prompts add git+https://github.com/acme/docli-prompts.git#v2 --name acme
+  docli prompts add https://intranet.example.com/docli/prompts --name intranet --user`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		user, _ := cmd.Flags().GetBool("user")
+		return runPromptsAdd(args[0], name, user)
+	},
+}
+
+func runPromptsAdd(source, name string, user bool) error {
+	if name == "" {
+		name = prompts.RegistryName(source)
+	}
+	registry := prompts.Registry{Name: name, Source: source}
+
+	project, userRegistries, err := declaredRegistries()
+	if err != nil {
+		return err
+	}
+	declare := declareProjectRegistry
+	if user {
+		declare = declareUserRegistry
+	} else if !spec.NewSpecRepo().SpecExists() {
+		return errs.SpecNotFound()
+	}
+
+	addCmd := prompts.NewAddPromptsCommand(registry, project, userRegistries, user, declare)
+	return addCmd.Run()
+}
+
+// declareProjectRegistry adds a prompt source to spec.json
+func declareProjectRegistry(registry prompts.Registry) error {
+	specRepo := spec.NewSpecRepo()
+	docSpec, err := specRepo.GetSpec()
+	if err != nil {
+		return err
+	}
+	docSpec.PromptSources = append(docSpec.PromptSources, registry)
+	return specRepo.Save(docSpec)
+}
+
+// declareUserRegistry adds a prompt source to the user config
+func declareUserRegistry(registry prompts.Registry) error {
+	configRepo := config.NewConfigRepo()
+	userConfig, err := configRepo.Load()
+	if err != nil {
+		return err
+	}
+	userConfig.PromptSources = append(userConfig.PromptSources, registry)
+	return configRepo.Save(userConfig)
+}
+
+func init() {
+	PromptsCmd.AddCommand(PromptsAddCmd)
+	PromptsAddCmd.Flags().String("name", "", "name of the prompt source (default: the directory or repository name)")
+	PromptsAddCmd.Flags().Bool("user", false, "declare the source in the user config instead of spec.json")
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var PromptsDiffCmd = &cobra.Command{
+	Use:   "diff [name...]",
+	Short: "Show how the local prompts differ from the source",
+	Long: `Print a unified diff from every local prompt to its version in the source,
+or only for the prompts named.
+
+Example:
+  docli prompts diff
+  docli prompts diff syncDoc.prompt.md`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPromptsDiff(cmd, args)
+	},
+}
+
+func runPromptsDiff(cmd *cobra.Command, names []string) error {
+	source, err := promptsSource(cmd)
+	if err != nil {
+		return err
+	}
+	diffCmd := prompts.NewDiffPromptsCommand(source, names)
+	return diffCmd.Run()
+}
+
+func init() {
+	PromptsCmd.AddCommand(PromptsDiffCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var PromptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the prompts and whether they are up to date",
+	Long: `List every prompt with its status:
+
+  current    the local file matches the source
+  modified   changed locally, the source has nothing new
+  outdated   the source has a newer version, no local changes
+  diverged   changed locally and in the source
+  missing    in the source but not installed
+  untracked  differs from the source and is not in the lock file
+  custom     exists only locally
+
+Example:
+  docli prompts list
+  docli prompts list --output json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPromptsList(cmd, outputFormat(cmd))
+	},
+}
+
+func runPromptsList(cmd *cobra.Command, output string) error {
+	source, err := promptsSource(cmd)
+	if err != nil {
+		return err
+	}
+	listCmd := prompts.NewListPromptsCommand(source, output)
+	return listCmd.Run()
+}
+
+func init() {
+	PromptsCmd.AddCommand(PromptsListCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/render"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+var PromptsRenderCmd = &cobra.Command{
+	Use:   "render <prompt> --doc <id>",
+	Short: "Render a prompt for one document",
+	Long: `Expand a prompt with everything about one document into a self-contained
+prompt on stdout, ready to pipe into any LLM tool.
+
+The prompt is taken from .github/prompts, or from --prompts-source when it is
+not installed. It can be a name like updateDoc or the path of a file.
+
+Prompts are Go text/template templates executed with the document:
+
+  {{ .ID }} {{ .Name }} {{ .Description }} {{ .Parent }} {{ .Labels }}
+  {{ .Properties }} {{ .Platforms }}
+  {{ .FileHints }}  every hint with .Hint, .Exists, .IsDir, .Glob, .Exclude,
+                    .Problem and .Files
+  {{ .Files }}      every file the hints resolve to
+  {{ .Skipped }}    the binary and oversized files left out, with .Path and
+                    .Reason
+  {{ .File }}       the generated file in .docs/
+  {{ .Content }}    its current content
+  {{ .Changes }}    the git changes of the hinted files since the last sync,
+                    with .Since, .Commits and .Diff, nil when never synced
+
+{{ template "context" . }} inserts all of it as markdown, and is appended to
+prompts that use none of it. The functions join, trim and fence are available,
+e.g. {{ fence .Content "markdown" }}.
+
+Example:
+  docli prompt render updateDoc --doc abc123
+  docli prompt render updateDoc --doc abc123 | llm
+  docli prompt render ./review.prompt.md --doc abc123 > prompt.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPromptsRender(cmd, args[0])
+	},
+}
+
+func runPromptsRender(cmd *cobra.Command, prompt string) error {
+	id, _ := cmd.Flags().GetString("doc")
+	if id == "" {
+		return errs.Usage("--doc is required, list the documents with 'docli list docmeta'")
+	}
+	source, err := promptsSource(cmd)
+	if err != nil {
+		return err
+	}
+	renderCmd := render.NewRenderPromptCommand(spec.NewSpecRepo(), prompt, id, source)
+	return renderCmd.Run()
+}
+
+func init() {
+	PromptsCmd.AddCommand(PromptsRenderCmd)
+	PromptsRenderCmd.Flags().String("doc", "", "ID of the document to render the prompt for")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var PromptsResetCmd = &cobra.Command{
+	Use:   "reset [name...]",
+	Short: "Replace the prompts with the version in the source",
+	Long: `Replace every prompt, or only the prompts named, with its version in the
+source. Local changes are discarded, confirmation is asked first unless
+--yes is given. Prompts that exist only locally are left alone.
+
+Example:
+  docli prompts reset syncDoc.prompt.md
+  docli prompts reset --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes, _ := cmd.Flags().GetBool("yes")
+		return runPromptsReset(cmd, args, yes)
+	},
+}
+
+func runPromptsReset(cmd *cobra.Command, names []string, yes bool) error {
+	source, err := promptsSource(cmd)
+	if err != nil {
+		return err
+	}
+
+	var confirm func(names []string) bool
+	if !yes {
+		reader := bufio.NewReader(os.Stdin)
+		confirm = func(names []string) bool {
+			return askYesNo(reader, "Discard your changes to %s? (y/N): ", strings.Join(names, ", "))
+		}
+	}
+
+	resetCmd := prompts.NewResetPromptsCommand(source, names, confirm)
+	return resetCmd.Run()
+}
+
+func init() {
+	PromptsCmd.AddCommand(PromptsResetCmd)
+	PromptsResetCmd.Flags().BoolP("yes", "y", false, "discard local changes without asking")
+}
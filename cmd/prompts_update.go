@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var PromptsUpdateCmd = &cobra.Command{
+	Use:   "update [name...]",
+	Short: "Update the prompts, keeping your changes",
+	Long: `Update every prompt, or only the prompts named, to the version in the source.
+
+Prompts you did not change are replaced and missing ones are installed.
+Prompts changed both by you and in the source get a three-way merge with
+the installed version as the base. When the changes overlap the local file is
+kept and the prompt is reported as a conflict; review it with
+'docli prompts diff' and take the new version with 'docli prompts reset'.
+
+Example:
+  docli prompts update
+  docli prompts update syncDoc.prompt.md
+  docli prompts update --prompts-source github`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPromptsUpdate(cmd, args)
+	},
+}
+
+func runPromptsUpdate(cmd *cobra.Command, names []string) error {
+	source, err := promptsSource(cmd)
+	if err != nil {
+		return err
+	}
+	updateCmd := prompts.NewUpdatePromptsCommand(source, names)
+	return updateCmd.Run()
+}
+
+func init() {
+	PromptsCmd.AddCommand(PromptsUpdateCmd)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/Hasankanso/docli/internal/confluence"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+// PruneCmd represents the prune command
+var PruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete Confluence pages that no longer belong to a document",
+	Long: `Find the pages docli created in the Confluence space that are no longer
+mapped to any document metadata entry, for example because the entry was
+deleted without --remote, and delete them.
+
+Only pages carrying the docli-managed label are considered, pages created
+by hand are never touched. The orphaned pages are listed and confirmation
+is asked before anything is deleted.
+
+Example:
+  docli prune --dry-run
+  docli prune --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		return runPrune(cmd, dryRun, yes)
+	},
+}
+
+func runPrune(cmd *cobra.Command, dryRun, yes bool) error {
+	client, spaceKey, err := newConfluenceClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	var confirm func(pages []goconfluence.Content) bool
+	if !yes {
+		reader := bufio.NewReader(os.Stdin)
+		confirm = func(pages []goconfluence.Content) bool {
+			return askYesNo(reader, "Delete these %d page(s)? (y/N): ", len(pages))
+		}
+	}
+
+	specRepo := spec.NewSpecRepo()
+	pruneCmd := confluence.NewPruneConfluenceCommand(specRepo, client, spaceKey, dryRun, confirm)
+	return pruneCmd.Run()
+}
+
+func init() {
+	RootCmd.AddCommand(PruneCmd)
+	addConfluenceFlags(PruneCmd)
+	PruneCmd.Flags().Bool("dry-run", false, "only list the orphaned pages")
+	PruneCmd.Flags().BoolP("yes", "y", false, "delete without asking")
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// PullCmd represents the pull command
+var PullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Pull documentation back from platforms",
+	Long: `Bring edits made directly on a platform back into the markdown files in .docs/.
+
+Available platforms:
+  confluence - Convert each document's Confluence page back into markdown
+
+Use the appropriate subcommand to pull from the specific platform you want to read.`,
+}
+
+func init() {
+	RootCmd.AddCommand(PullCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/confluence"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// PullConfluenceCmd represents the pull confluence command
+var PullConfluenceCmd = &cobra.Command{
+	Use:   "confluence",
+	Short: "Pull Confluence pages back into .docs/",
+	Long: `Fetch the Confluence page of every document metadata entry, convert it back
+into markdown and write it to the document's file in .docs/.
+A unified diff is shown and confirmation is asked before any local file is
+overwritten, unless --yes is given.
+
+Connection settings are the same as for 'docli sync confluence'.
+
+Example:
+  docli pull confluence --space DOCS
+  docli pull confluence --space DOCS --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes, _ := cmd.Flags().GetBool("yes")
+		return runPullConfluence(cmd, yes)
+	},
+}
+
+func runPullConfluence(cmd *cobra.Command, yes bool) error {
+	client, spaceKey, err := newConfluenceClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	var confirm func(docMeta spec.DocMetaData) bool
+	if !yes {
+		reader := bufio.NewReader(os.Stdin)
+		confirm = func(docMeta spec.DocMetaData) bool {
+			return askYesNo(reader, "Overwrite the local file of '%s'? (y/N): ", docMeta.Name)
+		}
+	}
+
+	specRepo := spec.NewSpecRepo()
+	pullCmd := confluence.NewPullConfluenceCommand(specRepo, client, spaceKey, confirm)
+	return pullCmd.Run()
+}
+
+func askYesNo(reader *bufio.Reader, format string, args ...interface{}) bool {
+	logger.Prompt(format, args...)
+	input, _ := reader.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
+
+func init() {
+	PullCmd.AddCommand(PullConfluenceCmd)
+	addConfluenceFlags(PullConfluenceCmd)
+	PullConfluenceCmd.Flags().BoolP("yes", "y", false, "overwrite local files without asking")
+}
@@ -3,8 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/output"
+	"github.com/Hasankanso/docli/internal/plan"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -18,24 +25,131 @@ Use docli to:
 - Generate documentation from source code
 - Convert between different documentation formats
 - Manage documentation workflows
-- Create and maintain project documentation`,
+- Create and maintain project documentation
+
+Exit codes:
+  0  success
+  1  general error
+  2  invalid flags or arguments
+  3  no documentation configuration, run 'docli init' first
+  4  invalid input, configuration or document metadata
+  5  document, page or profile not found
+  6  conflicting changes, nothing was overwritten
+  7  network error
+  8  authentication failed`,
+	// Errors are printed by Execute, with the exit code of their kind
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		started = true
+		err := setLogFormat(cmd)
+		if err != nil {
+			return err
+		}
+		setLogLevel(cmd)
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Welcome to docli! Use --help to see available commands.")
 	},
 }
 
+// started is set once flags and arguments were parsed and the command runs
+var started bool
+
+// setLogFormat switches the logger to the sink of --log-format. With a
+// machine --output format every message goes to stderr, stdout only carries
+// the output.
+func setLogFormat(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString("log-format")
+	out := os.Stdout
+	if machineOutput(cmd) {
+		out = os.Stderr
+	}
+	sink, err := logger.NewSink(format, out)
+	if err != nil {
+		return errs.Wrap(errs.KindUsage, err)
+	}
+	logger.SetSink(sink)
+	return nil
+}
+
+// setLogLevel applies DOCLI_LOG_LEVEL, then --verbose or --quiet on top of it.
+// --quiet leaves only errors, machine output is printed regardless.
+func setLogLevel(cmd *cobra.Command) {
+	level := logger.LevelInfo
+	if name := os.Getenv(logger.LevelEnv); name != "" {
+		parsed, err := logger.ParseLevel(name)
+		if err != nil {
+			logger.Warning("Ignoring %s: %v", logger.LevelEnv, err)
+		} else {
+			level = parsed
+		}
+	}
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		level = logger.LevelDebug
+	}
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		level = logger.LevelError
+	}
+	logger.SetLevel(level)
+	logger.Debug("Log level set to %s", level)
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Errors end the process with the exit code of their kind, see errs.Kind.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	err := RootCmd.Execute()
+	if err == nil {
+		return
+	}
+	if !started {
+		// Cobra rejected the flags or arguments before the command ran
+		err = errs.Wrap(errs.KindUsage, err)
+		logger.Error("%v", err)
+		logger.Info("Run 'docli --help' for usage")
+	} else {
+		logger.Error("%v", err)
+	}
+	os.Exit(errs.ExitCode(err))
+}
+
+// outputFormat returns the format of the global --output flag
+func outputFormat(cmd *cobra.Command) string {
+	format, _ := cmd.Flags().GetString("output")
+	return format
+}
+
+// localFlagsChanged tells whether any flag of the command itself was given.
+// Global flags like -v or --output are not counted, so that they do not turn
+// an interactive command into a non-interactive one. The flags named in
+// except are not counted either.
+func localFlagsChanged(cmd *cobra.Command, except ...string) bool {
+	changed := false
+	cmd.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if cmd.Flags().Changed(flag.Name) && !slices.Contains(except, flag.Name) {
+			changed = true
+		}
+	})
+	return changed
+}
+
+// machineOutput tells whether --output selects a format meant for programs
+func machineOutput(cmd *cobra.Command) bool {
+	switch outputFormat(cmd) {
+	case output.FormatTable, plan.FormatText, "":
+		return false
 	}
+	return true
 }
 
 func init() {
 
 	// Global flags
-	RootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
-	RootCmd.PersistentFlags().BoolP("quiet", "q", false, "quiet mode")
+	RootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output, also set by "+logger.LevelEnv+"=debug")
+	RootCmd.PersistentFlags().BoolP("quiet", "q", false, "quiet mode, only errors and machine output are printed")
+	RootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
+	RootCmd.PersistentFlags().String("log-format", logger.FormatText, "log format, text or json (one object per line on stderr)")
+	RootCmd.PersistentFlags().StringP("output", "o", output.FormatTable, "output format ("+strings.Join(output.Formats, ", ")+")")
 }
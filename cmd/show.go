@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ShowCmd represents the show command
+var ShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show resources in detail",
+	Long: `Show everything about a single resource of your documentation project.
+
+Available resource types:
+  docmeta - Show a document metadata entry by id
+
+Use the appropriate subcommand to show the specific type of resource you want to inspect.`,
+}
+
+func init() {
+	RootCmd.AddCommand(ShowCmd)
+}
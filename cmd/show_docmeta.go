@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/Hasankanso/docli/internal/docmeta"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// ShowDocmetaCmd represents the show docmeta command
+var ShowDocmetaCmd = &cobra.Command{
+	Use:   "docmeta <id>",
+	Short: "Show a document metadata entry by id",
+	Long: `Show everything about a document metadata entry: its description, its file
+hints and how many files each one resolves to, the generated file in .docs/,
+the platforms it is synced to and when, and whether the generated file is
+stale, i.e. older than the newest file among its hints.
+
+Example:
+  docli show docmeta abc123
+  docli show docmeta abc123 --output yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runShowDocmeta(args[0], outputFormat(cmd))
+	},
+}
+
+func runShowDocmeta(id, output string) error {
+	specRepo := spec.NewSpecRepo()
+	showCmd := docmeta.NewShowDocMetaCommand(specRepo, id, output)
+	return showCmd.Run()
+}
+
+func init() {
+	ShowCmd.AddCommand(ShowDocmetaCmd)
+}
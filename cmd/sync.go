@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// SyncCmd represents the sync command
+var SyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync documentation to platforms",
+	Long: `Push the generated documentation in .docs/ to your configured platforms.
+
+Available platforms:
+  confluence - Create or update one Confluence page per document metadata entry
+
+Use the appropriate subcommand to sync to the specific platform you want to update.
+Run 'docli plan' first to see what a sync would change.`,
+}
+
+func init() {
+	RootCmd.AddCommand(SyncCmd)
+}
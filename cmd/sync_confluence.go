@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Hasankanso/docli/internal/confluence"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/plan"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// SyncConfluenceCmd represents the sync confluence command
+var SyncConfluenceCmd = &cobra.Command{
+	Use:   "confluence",
+	Short: "Push every document to Confluence",
+	Long: `Create or update a Confluence page for every document metadata entry.
+Each document is read from its generated markdown file in .docs/ and published
+to the page recorded for it in spec.json. Documents without a recorded page, or
+whose page was deleted, are matched to a page with the same title in the
+configured space, and a new page is created when there is none. Renaming a
+document renames its page.
+
+Documents are published under their parent, which is either another document
+(by ID) or a Confluence page (by page ID). Parents are synced before their
+children, and pages whose parent changed are moved. Documents without a parent
+are published under the root page given with --root-page, which is created
+when missing and remembered in spec.json, or at the space root otherwise.
+
+Pages that were edited on Confluence since the last sync are never overwritten
+silently. A document changed only on Confluence is reported as outdated, and a
+document changed on both sides is reported as a conflict. Use --force-local,
+--force-remote or --merge to resolve them.
+
+With --dry-run nothing is changed on Confluence or locally. The planned action
+and body diff of every document is printed instead, as text or as JSON or
+YAML with --output json|yaml.
+
+Connection settings are taken from the flags, then from the DOCLI_CONFLUENCE_*
+environment variables, then from the config profile (see 'docli config'). The
+API token is never accepted as a flag.
+
+Example:
+  docli sync confluence --url https://example.atlassian.net/wiki/rest/api --username me@example.com --space DOCS
+  docli sync confluence --space DOCS --merge
+  docli sync confluence --space DOCS --root-page "Project Docs"
+  docli sync confluence --space DOCS --dry-run --output json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resolution := confluence.ResolveNone
+		if forceLocal, _ := cmd.Flags().GetBool("force-local"); forceLocal {
+			resolution = confluence.ResolveLocal
+		}
+		if forceRemote, _ := cmd.Flags().GetBool("force-remote"); forceRemote {
+			resolution = confluence.ResolveRemote
+		}
+		if merge, _ := cmd.Flags().GetBool("merge"); merge {
+			resolution = confluence.ResolveMerge
+		}
+		rootPage, _ := cmd.Flags().GetString("root-page")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		return runSyncConfluence(cmd, resolution, rootPage, dryRun)
+	},
+}
+
+func runSyncConfluence(cmd *cobra.Command, resolution, rootPage string, dryRun bool) error {
+	client, spaceKey, err := newConfluenceClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	specRepo := spec.NewSpecRepo()
+	syncCmd := confluence.NewSyncConfluenceCommand(specRepo, client, spaceKey, resolution, rootPage)
+	if !dryRun {
+		return syncCmd.Run()
+	}
+
+	syncPlan, err := syncCmd.Plan()
+	if err != nil {
+		return fmt.Errorf("failed to plan Confluence sync: %w", err)
+	}
+	err = plan.Write(os.Stdout, outputFormat(cmd), []*plan.Plan{syncPlan})
+	if err != nil {
+		return errs.Wrap(errs.KindUsage, fmt.Errorf("failed to print the plan: %w", err))
+	}
+	return nil
+}
+
+func init() {
+	SyncCmd.AddCommand(SyncConfluenceCmd)
+	addConfluenceFlags(SyncConfluenceCmd)
+	SyncConfluenceCmd.Flags().Bool("force-local", false, "overwrite pages that changed on Confluence with the local files")
+	SyncConfluenceCmd.Flags().Bool("force-remote", false, "overwrite local files with pages that changed on Confluence")
+	SyncConfluenceCmd.Flags().Bool("merge", false, "three-way merge documents that changed on both sides")
+	SyncConfluenceCmd.MarkFlagsMutuallyExclusive("force-local", "force-remote", "merge")
+	SyncConfluenceCmd.Flags().String("root-page", "", "title of a page to publish all documents without a parent under")
+	SyncConfluenceCmd.Flags().Bool("dry-run", false, "print the planned changes without applying them")
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// UpdateCmd represents the update command
+var UpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update resources",
+	Long: `Update various types of resources in your documentation project.
+
+Available resource types:
+  docmeta - Update a document metadata entry by id
+
+Use the appropriate subcommand to update the specific type of resource you want to change.`,
+}
+
+func init() {
+	RootCmd.AddCommand(UpdateCmd)
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/docmeta"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/spec"
+	"github.com/spf13/cobra"
+)
+
+// UpdateDocmetaCmd represents the update docmeta command
+var UpdateDocmetaCmd = &cobra.Command{
+	Use:   "docmeta <id>",
+	Short: "Update a document metadata entry by id",
+	Long: `Update a document metadata entry in your spec.md file.
+
+Without flags the current values are shown one by one, press Enter to keep
+a value, -i does the same and cannot be combined with other flags. With flags
+only the given fields change. Renaming a document moves
+its generated markdown file along. Labels and properties are applied to the
+Confluence page on the next sync, labels removed here are removed from the
+page as well.
+
+Example:
+  docli update docmeta abc123
+  docli update docmeta abc123 --name "API Reference" --description "REST endpoints"
+  docli update docmeta abc123 --hint api/ --remove-hint old/
+  docli update docmeta abc123 --label api --remove-label draft
+  docli update docmeta abc123 --property owner=platform-team --remove-property status`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		fieldsChanged := localFlagsChanged(cmd, "interactive")
+		if interactive && fieldsChanged {
+			return errs.Validation("--interactive cannot be combined with flags that change fields")
+		}
+		if !fieldsChanged {
+			return runUpdateDocmetaInteractive(args[0])
+		}
+		return runUpdateDocmeta(cmd, args[0])
+	},
+}
+
+func runUpdateDocmeta(cmd *cobra.Command, id string) error {
+	var changes docmeta.DocMetaChanges
+	for _, field := range []struct {
+		flag  string
+		value **string
+	}{
+		{"name", &changes.Name},
+		{"description", &changes.Description},
+		{"parent", &changes.Parent},
+	} {
+		if cmd.Flags().Changed(field.flag) {
+			value, _ := cmd.Flags().GetString(field.flag)
+			value = strings.TrimSpace(value)
+			*field.value = &value
+		}
+	}
+	changes.AddHints, _ = cmd.Flags().GetStringArray("hint")
+	if len(changes.AddHints) > 0 {
+		warnUnresolvedHints(id, changes.AddHints)
+	}
+	changes.RemoveHints, _ = cmd.Flags().GetStringArray("remove-hint")
+	changes.AddLabels, _ = cmd.Flags().GetStringArray("label")
+	changes.RemoveLabels, _ = cmd.Flags().GetStringArray("remove-label")
+	changes.RemoveProperties, _ = cmd.Flags().GetStringArray("remove-property")
+
+	properties, _ := cmd.Flags().GetStringArray("property")
+	for _, property := range properties {
+		key, value, err := spec.ParseProperty(property)
+		if err != nil {
+			return errs.Wrap(errs.KindUsage, err)
+		}
+		if changes.SetProperties == nil {
+			changes.SetProperties = map[string]string{}
+		}
+		changes.SetProperties[key] = value
+	}
+
+	specRepo := spec.NewSpecRepo()
+	updateCmd := docmeta.NewUpdateDocMetaCommand(specRepo, id, changes)
+	return updateCmd.Run()
+}
+
+func runUpdateDocmetaInteractive(id string) error {
+	specRepo := spec.NewSpecRepo()
+	if !specRepo.SpecExists() {
+		return errs.SpecNotFound()
+	}
+	doc, err := specRepo.GetDocMeta(id)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	changes := CollectDocumentUpdates(reader, doc)
+	updateCmd := docmeta.NewUpdateDocMetaCommand(specRepo, id, changes)
+	return updateCmd.Run()
+}
+
+// CollectDocumentUpdates asks for new values of a document, showing the
+// current ones. Pressing Enter keeps a value.
+func CollectDocumentUpdates(reader *bufio.Reader, doc *spec.DocMetaData) docmeta.DocMetaChanges {
+	var changes docmeta.DocMetaChanges
+	logger.Info("\n--- Update Document Configuration ---")
+	logger.Info("Press Enter to keep the current value, enter - to clear it.")
+
+	ask := func(prompt, current string) *string {
+		logger.Prompt("%s [%s]: ", prompt, current)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		switch input {
+		case "":
+			return nil
+		case "-":
+			input = ""
+		}
+		return &input
+	}
+
+	changes.Name = ask("Enter document title", doc.Name)
+	if changes.Name != nil && *changes.Name == "" {
+		logger.Warning("A document needs a title, keeping '%s'", doc.Name)
+		changes.Name = nil
+	}
+	changes.Description = ask("Enter description", doc.Description)
+
+	if len(doc.FileHints) > 0 {
+		logger.Info("\nCurrent file/folder hints:")
+		for _, hint := range doc.FileHints {
+			if !askYesNoDefault(reader, true, "  Keep '%s'? (Y/n): ", hint) {
+				changes.RemoveHints = append(changes.RemoveHints, hint)
+			}
+		}
+	}
+	logger.Info("\nAdd file or folder hints. Press Enter on an empty line when done.")
+	for {
+		logger.Prompt("  File/Folder (or press Enter to finish): ")
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			break
+		}
+		if hint := checkHint(reader, input); hint != "" {
+			changes.AddHints = append(changes.AddHints, hint)
+		}
+	}
+
+	changes.Parent = ask("\nEnter the parent, a document ID or a Confluence page ID", doc.Parent)
+
+	if labels := ask("\nEnter labels, separated by commas", strings.Join(doc.Labels, ", ")); labels != nil {
+		// The new list replaces the current labels
+		changes.RemoveLabels = doc.Labels
+		changes.AddLabels = strings.Split(*labels, ",")
+	}
+
+	if len(doc.Properties) > 0 {
+		logger.Info("\nCurrent page properties:")
+		for _, key := range doc.PropertyKeys() {
+			if !askYesNoDefault(reader, true, "  Keep '%s = %s'? (Y/n): ", key, doc.Properties[key]) {
+				changes.RemoveProperties = append(changes.RemoveProperties, key)
+			}
+		}
+	}
+	logger.Info("\nSet page properties as key=value. Press Enter on an empty line when done.")
+	for {
+		logger.Prompt("  Property (or press Enter to finish): ")
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			break
+		}
+		key, value, err := spec.ParseProperty(input)
+		if err != nil {
+			logger.Warning("%v", err)
+			continue
+		}
+		if changes.SetProperties == nil {
+			changes.SetProperties = map[string]string{}
+		}
+		changes.SetProperties[key] = value
+		// Removals are applied last, a property set again must not be removed
+		changes.RemoveProperties = slices.DeleteFunc(changes.RemoveProperties, func(removed string) bool {
+			return removed == key
+		})
+	}
+
+	return changes
+}
+
+// askYesNoDefault asks a yes/no question, an empty answer picks the default
+func askYesNoDefault(reader *bufio.Reader, defaultYes bool, format string, args ...interface{}) bool {
+	logger.Prompt(format, args...)
+	input, _ := reader.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" {
+		return defaultYes
+	}
+	return input == "y" || input == "yes"
+}
+
+func init() {
+	UpdateCmd.AddCommand(UpdateDocmetaCmd)
+	UpdateDocmetaCmd.Flags().BoolP("interactive", "i", false, "ask for every field, showing the current values")
+	UpdateDocmetaCmd.Flags().String("name", "", "rename the document")
+	UpdateDocmetaCmd.Flags().String("description", "", "change the description")
+	UpdateDocmetaCmd.Flags().String("parent", "", "change the parent, a document ID or a Confluence page ID")
+	UpdateDocmetaCmd.Flags().StringArray("hint", nil, "add a file or folder hint, can be repeated")
+	UpdateDocmetaCmd.Flags().StringArray("remove-hint", nil, "remove a file or folder hint, can be repeated")
+	UpdateDocmetaCmd.Flags().StringArray("label", nil, "add a label, can be repeated")
+	UpdateDocmetaCmd.Flags().StringArray("remove-label", nil, "remove a label, can be repeated")
+	UpdateDocmetaCmd.Flags().StringArray("property", nil, "set a page property as key=value, can be repeated")
+	UpdateDocmetaCmd.Flags().StringArray("remove-property", nil, "remove a page property by key, can be repeated")
+}
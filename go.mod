@@ -5,11 +5,13 @@ go 1.24.1
 require (
 	github.com/lucsky/cuid v1.2.1
 	github.com/spf13/cobra v1.10.1
+	github.com/spf13/pflag v1.0.9
 	github.com/virtomize/confluence-go-api v1.5.1
+	github.com/yuin/goldmark v1.8.6
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/magefile/mage v1.14.0 // indirect
-	github.com/spf13/pflag v1.0.9 // indirect
 )
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Hasankanso/docli/internal/confluence"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+)
+
+type SetConfigCommand struct {
+	ConfigRepo *ConfigRepo
+	Profile    string
+	Key        string
+	Value      string
+}
+
+func NewSetConfigCommand(configRepo *ConfigRepo, profile, key, value string) *SetConfigCommand {
+	return &SetConfigCommand{
+		ConfigRepo: configRepo,
+		Profile:    profile,
+		Key:        key,
+		Value:      value,
+	}
+}
+
+func (cmd *SetConfigCommand) Run() error {
+	setting, err := LookupSetting(cmd.Key)
+	if err != nil {
+		return errs.Wrap(errs.KindValidation, err)
+	}
+
+	config, err := cmd.ConfigRepo.Load()
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	name := config.ProfileName(cmd.Profile)
+	if config.Profiles == nil {
+		config.Profiles = map[string]*Profile{}
+	}
+	profile, found := config.Profiles[name]
+	if !found {
+		profile = &Profile{}
+		config.Profiles[name] = profile
+	}
+	if config.CurrentProfile == "" {
+		config.CurrentProfile = name
+	}
+	setting.Set(profile, cmd.Value)
+
+	err = cmd.ConfigRepo.Save(config)
+	if err != nil {
+		return fmt.Errorf("error saving configuration: %w", err)
+	}
+	logger.Success("Set %s in profile '%s'", setting.Key, name)
+	return nil
+}
+
+type GetConfigCommand struct {
+	ConfigRepo *ConfigRepo
+	Profile    string
+	Key        string
+	ShowSecret bool
+}
+
+func NewGetConfigCommand(configRepo *ConfigRepo, profile, key string, showSecret bool) *GetConfigCommand {
+	return &GetConfigCommand{
+		ConfigRepo: configRepo,
+		Profile:    profile,
+		Key:        key,
+		ShowSecret: showSecret,
+	}
+}
+
+func (cmd *GetConfigCommand) Run() error {
+	setting, err := LookupSetting(cmd.Key)
+	if err != nil {
+		return errs.Wrap(errs.KindValidation, err)
+	}
+
+	config, err := cmd.ConfigRepo.Load()
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	name := config.ProfileName(cmd.Profile)
+	profile, found := config.Profiles[name]
+	if !found {
+		return errs.NotFound("profile '%s' not found in %s", name, cmd.ConfigRepo.ConfigFilePath)
+	}
+
+	value := setting.Get(profile)
+	if !cmd.ShowSecret {
+		value = setting.Mask(value)
+	}
+	fmt.Println(value)
+	return nil
+}
+
+type ListConfigCommand struct {
+	ConfigRepo *ConfigRepo
+}
+
+func NewListConfigCommand(configRepo *ConfigRepo) *ListConfigCommand {
+	return &ListConfigCommand{
+		ConfigRepo: configRepo,
+	}
+}
+
+func (cmd *ListConfigCommand) Run() error {
+	config, err := cmd.ConfigRepo.Load()
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	logger.Info("Configuration file: %s", cmd.ConfigRepo.ConfigFilePath)
+	if len(config.Profiles) == 0 {
+		logger.Info("No profiles configured yet, create one with 'docli config set <key> <value>'")
+	}
+
+	current := config.ProfileName("")
+	for _, name := range config.ProfileNames() {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		logger.Info("")
+		logger.Info("%s %s", marker, name)
+		for _, setting := range Settings {
+			if value := setting.Get(config.Profiles[name]); value != "" {
+				logger.Info("    %-22s %s", setting.Key, setting.Mask(value))
+			}
+		}
+	}
+
+	var overrides []Setting
+	for _, setting := range Settings {
+		if os.Getenv(setting.Env) != "" {
+			overrides = append(overrides, setting)
+		}
+	}
+	if len(overrides) > 0 {
+		logger.Info("")
+		logger.Info("Overridden by the environment:")
+		for _, setting := range overrides {
+			logger.Info("    %-22s %s (%s)", setting.Key, setting.Mask(os.Getenv(setting.Env)), setting.Env)
+		}
+	}
+	return nil
+}
+
+type UseConfigCommand struct {
+	ConfigRepo *ConfigRepo
+	Profile    string
+}
+
+func NewUseConfigCommand(configRepo *ConfigRepo, profile string) *UseConfigCommand {
+	return &UseConfigCommand{
+		ConfigRepo: configRepo,
+		Profile:    profile,
+	}
+}
+
+func (cmd *UseConfigCommand) Run() error {
+	config, err := cmd.ConfigRepo.Load()
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	if !config.HasProfile(cmd.Profile) {
+		return errs.NotFound("profile '%s' not found in %s, create it with 'docli config set <key> <value> --profile %s'",
+			cmd.Profile, cmd.ConfigRepo.ConfigFilePath, cmd.Profile)
+	}
+
+	config.CurrentProfile = cmd.Profile
+	err = cmd.ConfigRepo.Save(config)
+	if err != nil {
+		return fmt.Errorf("error saving configuration: %w", err)
+	}
+	logger.Success("Now using profile '%s'", cmd.Profile)
+	return nil
+}
+
+type TestConfigCommand struct {
+	ConfigRepo *ConfigRepo
+	Profile    string
+}
+
+func NewTestConfigCommand(configRepo *ConfigRepo, profile string) *TestConfigCommand {
+	return &TestConfigCommand{
+		ConfigRepo: configRepo,
+		Profile:    profile,
+	}
+}
+
+func (cmd *TestConfigCommand) Run() error {
+	settings, err := cmd.ConfigRepo.Confluence(cmd.Profile)
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	if settings.URL == "" {
+		return errs.Validation("no Confluence URL configured, set it with 'docli config set confluence.url <url>'")
+	}
+
+	logger.Info("Connecting to %s...", settings.URL)
+	client, err := confluence.NewConfluenceClient(settings.URL, settings.Username, settings.APIToken)
+	if err != nil {
+		return errs.Validation("failed to create Confluence client: %w", err)
+	}
+
+	user, err := client.CurrentUser()
+	if err != nil {
+		return errs.Wrap(errs.KindAuth, fmt.Errorf("failed to authenticate with Confluence: %w", err))
+	}
+	logger.Info("Authenticated as %s", userName(user.DisplayName, user.Username))
+
+	if settings.Space == "" {
+		logger.Warning("No Confluence space configured, set it with 'docli config set confluence.space <key>'")
+		return nil
+	}
+	space, err := client.GetSpace(settings.Space)
+	if errors.Is(err, confluence.ErrSpaceNotFound) {
+		return errs.NotFound("space '%s' not found", settings.Space)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to access space '%s': %w", settings.Space, err)
+	}
+	logger.Success("Connected to Confluence space '%s' (%s)", space.Key, space.Name)
+	return nil
+}
+
+func userName(displayName, username string) string {
+	if displayName != "" {
+		return displayName
+	}
+	if username != "" {
+		return username
+	}
+	return "an anonymous user"
+}
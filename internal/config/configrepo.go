@@ -0,0 +1,234 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/prompts"
+)
+
+// DefaultProfile is used when no profile has been selected
+const DefaultProfile = "default"
+
+// Config is the user level docli configuration. It lives outside of the
+// project so that credentials never end up in the repository.
+type Config struct {
+	CurrentProfile string              "json:\"current_profile,omitempty\""
+	Profiles       map[string]*Profile "json:\"profiles,omitempty\""
+	// PromptSources are prompt registries available to every project
+	PromptSources []prompts.Registry "json:\"prompt_sources,omitempty\""
+}
+
+// Profile is a named set of connection settings
+type Profile struct {
+	Confluence ConfluenceSettings "json:\"confluence\""
+	LLM        LLMSettings        "json:\"llm,omitzero\""
+}
+
+// ConfluenceSettings holds everything needed to connect to a Confluence space
+type ConfluenceSettings struct {
+	URL      string "json:\"url,omitempty\""
+	Username string "json:\"username,omitempty\""
+	APIToken string "json:\"api_token,omitempty\""
+	Space    string "json:\"space,omitempty\""
+}
+
+// LLMSettings holds everything needed to call an OpenAI compatible chat
+// completions API, e.g. OpenAI itself or a local server
+type LLMSettings struct {
+	// URL is the base URL of the API, the part before /chat/completions
+	URL    string "json:\"url,omitempty\""
+	APIKey string "json:\"api_key,omitempty\""
+	Model  string "json:\"model,omitempty\""
+	// ContextTokens is the size of the context window of the model
+	ContextTokens string "json:\"context_tokens,omitempty\""
+}
+
+// Setting describes a key that can be read and written with 'docli config'
+type Setting struct {
+	Key string
+	// Env is the environment variable that overrides the stored value
+	Env    string
+	Secret bool
+	field  func(profile *Profile) *string
+}
+
+// Settings lists every configurable key
+var Settings = []Setting{
+	{Key: "confluence.url", Env: "DOCLI_CONFLUENCE_URL", field: func(p *Profile) *string { return &p.Confluence.URL }},
+	{Key: "confluence.username", Env: "DOCLI_CONFLUENCE_USERNAME", field: func(p *Profile) *string { return &p.Confluence.Username }},
+	{Key: "confluence.api_token", Env: "DOCLI_CONFLUENCE_API_TOKEN", Secret: true, field: func(p *Profile) *string { return &p.Confluence.APIToken }},
+	{Key: "confluence.space", Env: "DOCLI_CONFLUENCE_SPACE", field: func(p *Profile) *string { return &p.Confluence.Space }},
+	{Key: "llm.url", Env: "DOCLI_LLM_URL", field: func(p *Profile) *string { return &p.LLM.URL }},
+	{Key: "llm.api_key", Env: "DOCLI_LLM_API_KEY", Secret: true, field: func(p *Profile) *string { return &p.LLM.APIKey }},
+	{Key: "llm.model", Env: "DOCLI_LLM_MODEL", field: func(p *Profile) *string { return &p.LLM.Model }},
+	{Key: "llm.context_tokens", Env: "DOCLI_LLM_CONTEXT_TOKENS", field: func(p *Profile) *string { return &p.LLM.ContextTokens }},
+}
+
+// LookupSetting returns the setting with the given key
+func LookupSetting(key string) (Setting, error) {
+	for _, setting := range Settings {
+		if setting.Key == key {
+			return setting, nil
+		}
+	}
+	keys := make([]string, 0, len(Settings))
+	for _, setting := range Settings {
+		keys = append(keys, setting.Key)
+	}
+	return Setting{}, fmt.Errorf("unknown config key '%s', expected one of: %s", key, strings.Join(keys, ", "))
+}
+
+// Get returns the value of the setting stored in a profile
+func (s Setting) Get(profile *Profile) string {
+	return *s.field(profile)
+}
+
+// Set changes the value of the setting in a profile
+func (s Setting) Set(profile *Profile, value string) {
+	*s.field(profile) = value
+}
+
+// Mask hides secret values, keeping just enough to recognise them
+func (s Setting) Mask(value string) string {
+	if !s.Secret || value == "" {
+		return value
+	}
+	if len(value) <= 8 {
+		return "********"
+	}
+	return "****" + value[len(value)-4:]
+}
+
+type ConfigRepo struct {
+	ConfigFilePath string
+}
+
+// NewConfigRepo uses the file named by DOCLI_CONFIG, or config.json in the
+// docli directory of the user's configuration directory
+func NewConfigRepo() *ConfigRepo {
+	path := os.Getenv("DOCLI_CONFIG")
+	if path == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			configDir = "."
+		}
+		path = filepath.Join(configDir, "docli", "config.json")
+	}
+	return &ConfigRepo{ConfigFilePath: path}
+}
+
+// Load reads the configuration, a missing file is an empty configuration
+func (r *ConfigRepo) Load() (*Config, error) {
+	config := &Config{}
+	data, err := os.ReadFile(r.ConfigFilePath)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", r.ConfigFilePath, err)
+	}
+	err = json.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", r.ConfigFilePath, err)
+	}
+	return config, nil
+}
+
+// Save writes the configuration readable by the current user only, since it
+// holds API tokens
+func (r *ConfigRepo) Save(config *Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(r.ConfigFilePath), 0700)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(r.ConfigFilePath, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(r.ConfigFilePath, 0600)
+}
+
+// ProfileName resolves which profile to use: the given name, then
+// DOCLI_PROFILE, then the current profile of the configuration
+func (c *Config) ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if name := os.Getenv("DOCLI_PROFILE"); name != "" {
+		return name
+	}
+	if c.CurrentProfile != "" {
+		return c.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// ProfileNames returns the names of all profiles in alphabetical order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasProfile reports whether a profile with the given name exists
+func (c *Config) HasProfile(name string) bool {
+	_, found := c.Profiles[name]
+	return found
+}
+
+// Confluence resolves the Confluence settings of a profile with the
+// DOCLI_CONFLUENCE_* environment variables taking precedence over stored
+// values. A profile that was asked for explicitly must exist.
+func (r *ConfigRepo) Confluence(profileName string) (*ConfluenceSettings, error) {
+	profile, err := r.resolve(profileName)
+	if err != nil {
+		return nil, err
+	}
+	return &profile.Confluence, nil
+}
+
+// LLM resolves the LLM settings of a profile like Confluence does, with the
+// DOCLI_LLM_* environment variables
+func (r *ConfigRepo) LLM(profileName string) (*LLMSettings, error) {
+	profile, err := r.resolve(profileName)
+	if err != nil {
+		return nil, err
+	}
+	return &profile.LLM, nil
+}
+
+// resolve returns a copy of a profile with the environment variables of
+// every setting applied
+func (r *ConfigRepo) resolve(profileName string) (*Profile, error) {
+	config, err := r.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	name := config.ProfileName(profileName)
+	profile := &Profile{}
+	if stored, found := config.Profiles[name]; found {
+		*profile = *stored
+	} else if profileName != "" {
+		return nil, fmt.Errorf("profile '%s' not found in %s", profileName, r.ConfigFilePath)
+	}
+
+	for _, setting := range Settings {
+		if value := os.Getenv(setting.Env); value != "" {
+			setting.Set(profile, value)
+		}
+	}
+	return profile, nil
+}
@@ -0,0 +1,198 @@
+package confluence
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/converter"
+	"github.com/Hasankanso/docli/internal/hints"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+// localAttachment is a local image or file referenced by a document
+type localAttachment struct {
+	// reference is the target as written in the markdown
+	reference string
+	path      string
+	// filename is the name of the page attachment the file is uploaded as
+	filename string
+	hash     string
+	content  []byte
+	// attachmentID is set when the page already has an attachment with the
+	// same file name
+	attachmentID string
+}
+
+// collectAttachments finds the local images and files referenced by a
+// document. Links to other documents and references to files that do not
+// exist are left alone, and files outside of the project directory or
+// ignored by .gitignore are never uploaded. Files with the same content share
+// one attachment, files with the same name but different content get the
+// hash appended.
+func collectAttachments(projectDir, docPath, markdown string, options *linkOptions) ([]localAttachment, error) {
+	var attachments []localAttachment
+	filenames := map[string]string{}
+	byHash := map[string]string{}
+
+	for _, reference := range converter.LocalReferences(markdown) {
+		if _, isDoc := options.storage.PageTitle(reference); isDoc || strings.EqualFold(filepath.Ext(reference), ".md") {
+			continue
+		}
+		unescaped, err := url.PathUnescape(reference)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(unescaped, "/") || filepath.IsAbs(unescaped) {
+			logger.Warning("Not uploading %s referenced by %s, absolute paths are not uploaded", reference, docPath)
+			continue
+		}
+		path := filepath.Join(filepath.Dir(docPath), filepath.FromSlash(unescaped))
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if problem := attachmentProblem(projectDir, path); problem != "" {
+			logger.Warning("Not uploading %s referenced by %s, %s", reference, docPath, problem)
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		hash := spec.ContentHash(string(content))
+
+		filename, seen := byHash[hash]
+		if !seen {
+			filename = filepath.Base(path)
+			if _, taken := filenames[filename]; taken {
+				extension := filepath.Ext(filename)
+				filename = fmt.Sprintf("%s-%s%s", strings.TrimSuffix(filename, extension), hash[:8], extension)
+			}
+			filenames[filename] = hash
+			byHash[hash] = filename
+		}
+
+		attachments = append(attachments, localAttachment{
+			reference: reference,
+			path:      path,
+			filename:  filename,
+			hash:      hash,
+			content:   content,
+		})
+	}
+	return attachments, nil
+}
+
+// attachmentProblem tells why a referenced file must not be uploaded, empty
+// when it can be
+func attachmentProblem(projectDir, path string) string {
+	// Symbolic links are followed so that they cannot point out of the project
+	root, err := filepath.EvalSymlinks(projectDir)
+	if err != nil {
+		return fmt.Sprintf("the project directory cannot be resolved: %v", err)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Sprintf("it cannot be resolved: %v", err)
+	}
+	root, _ = filepath.Abs(root)
+	resolved, _ = filepath.Abs(resolved)
+	relative, err := filepath.Rel(root, resolved)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "it is outside of the project"
+	}
+	if hints.Ignored(root, relative) {
+		return "it is ignored by .gitignore"
+	}
+	return ""
+}
+
+// withAttachments returns link options that also resolve references to the
+// attachments of a document, in both conversion directions
+func (o *linkOptions) withAttachments(attachments []localAttachment) *linkOptions {
+	storage := *o.storage
+	storage.Attachment = func(target string) (string, bool) {
+		for _, attachment := range attachments {
+			if attachment.reference == target {
+				return attachment.filename, true
+			}
+		}
+		return "", false
+	}
+
+	markdown := *o.markdown
+	markdown.AttachmentFile = func(filename string) (string, bool) {
+		for _, attachment := range attachments {
+			if attachment.filename == filename {
+				return attachment.reference, true
+			}
+		}
+		return "", false
+	}
+	return &linkOptions{storage: &storage, markdown: &markdown}
+}
+
+// pendingUploads returns the attachments that are missing on the page or
+// changed since they were last uploaded
+func pendingUploads(attachments []localAttachment, uploaded map[string]string, existing []PageAttachment) []localAttachment {
+	var uploads []localAttachment
+	done := map[string]bool{}
+	for _, attachment := range attachments {
+		if done[attachment.filename] {
+			continue
+		}
+		done[attachment.filename] = true
+
+		for _, remote := range existing {
+			if remote.Filename == attachment.filename {
+				attachment.attachmentID = remote.ID
+			}
+		}
+		if attachment.attachmentID == "" || uploaded[attachment.filename] != attachment.hash {
+			uploads = append(uploads, attachment)
+		}
+	}
+	return uploads
+}
+
+// uploadAttachments uploads new attachments and new versions of changed ones
+func uploadAttachments(client *ConfluenceClient, pageID string, uploads []localAttachment) error {
+	for _, attachment := range uploads {
+		var err error
+		if attachment.attachmentID != "" {
+			err = client.UpdateAttachment(pageID, attachment.attachmentID, attachment.filename, bytes.NewReader(attachment.content))
+		} else {
+			err = client.UploadAttachment(pageID, attachment.filename, bytes.NewReader(attachment.content))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to upload %s as attachment %s: %w", attachment.path, attachment.filename, err)
+		}
+	}
+	return nil
+}
+
+// attachmentHashes maps the file name of every attachment to its hash
+func attachmentHashes(attachments []localAttachment) map[string]string {
+	if len(attachments) == 0 {
+		return nil
+	}
+	hashes := map[string]string{}
+	for _, attachment := range attachments {
+		hashes[attachment.filename] = attachment.hash
+	}
+	return hashes
+}
+
+func attachmentNames(attachments []localAttachment) string {
+	names := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		names = append(names, attachment.filename)
+	}
+	return strings.Join(names, ", ")
+}
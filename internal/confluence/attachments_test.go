@@ -0,0 +1,58 @@
+package confluence
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCollectAttachmentsStaysInProject(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "project")
+	files := map[string]string{
+		"outside.env":              "TOKEN=secret",
+		"project/.gitignore":       "secrets/\n*.key\n",
+		"project/img/arch.png":     "png",
+		"project/secrets/prod.txt": "password",
+		"project/deploy.key":       "key",
+		"project/.docs/notes.txt":  "notes",
+	}
+	for name, content := range files {
+		path := filepath.Join(base, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	err := os.Symlink(filepath.Join(base, "outside.env"), filepath.Join(project, "img", "linked.png"))
+	if err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	markdown := `# Architecture
+
+![diagram](../img/arch.png)
+![notes](notes.txt)
+![env](../../outside.env)
+![absolute](` + filepath.ToSlash(filepath.Join(base, "outside.env")) + `)
+![secret](../secrets/prod.txt)
+![key](../deploy.key)
+![linked](../img/linked.png)
+`
+	docPath := filepath.Join(project, ".docs", "architecture.md")
+	attachments, err := collectAttachments(project, docPath, markdown, newLinkOptions(nil))
+	if err != nil {
+		t.Fatalf("collectAttachments: %v", err)
+	}
+	var references []string
+	for _, attachment := range attachments {
+		references = append(references, attachment.reference)
+	}
+	if want := []string{"../img/arch.png", "notes.txt"}; !slices.Equal(references, want) {
+		t.Errorf("attachments = %v, want %v", references, want)
+	}
+}
@@ -1,11 +1,27 @@
 package confluence
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
 
 	goconfluence "github.com/virtomize/confluence-go-api"
 )
 
+// ErrPageNotFound is returned when a page lookup has no results
+var ErrPageNotFound = errors.New("page not found")
+
+// ErrSpaceNotFound is returned when a space lookup has no results
+var ErrSpaceNotFound = errors.New("space not found")
+
 type ConfluenceClient struct {
 	BaseURL   string
 	APIToken  string
@@ -18,6 +34,7 @@ func NewConfluenceClient(baseURL, username, apiToken string) (*ConfluenceClient,
 	if err != nil {
 		return nil, err
 	}
+	api.Client.Transport = &debugTransport{next: api.Client.Transport}
 	return &ConfluenceClient{
 		BaseURL:   baseURL,
 		APIToken:  apiToken,
@@ -26,6 +43,30 @@ func NewConfluenceClient(baseURL, username, apiToken string) (*ConfluenceClient,
 	}, nil
 }
 
+// debugTransport logs every request made to Confluence at debug level
+type debugTransport struct {
+	next http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+	duration := time.Since(start)
+	fields := logger.Fields{
+		"platform":    "confluence",
+		"method":      request.Method,
+		"url":         request.URL.Redacted(),
+		"duration_ms": duration.Milliseconds(),
+	}
+	if err != nil {
+		logger.With(fields).Debug("%s %s failed after %s: %v", request.Method, request.URL.Redacted(), duration.Round(time.Millisecond), err)
+		return nil, err
+	}
+	fields["status"] = response.StatusCode
+	logger.With(fields).Debug("%s %s -> %s in %s", request.Method, request.URL.Redacted(), response.Status, duration.Round(time.Millisecond))
+	return response, nil
+}
+
 func (c *ConfluenceClient) CreatePage(page *CreateConfluencePage) (*goconfluence.Content, error) {
 	content, err := c.apiClient.CreateContent(&goconfluence.Content{
 		Type:  "page",
@@ -33,6 +74,7 @@ func (c *ConfluenceClient) CreatePage(page *CreateConfluencePage) (*goconfluence
 		Space: &goconfluence.Space{
 			Key: page.SpaceKey,
 		},
+		Ancestors: ancestors(page.ParentPageID),
 		Body: goconfluence.Body{
 			Storage: goconfluence.Storage{
 				Value:          page.Body,
@@ -41,16 +83,17 @@ func (c *ConfluenceClient) CreatePage(page *CreateConfluencePage) (*goconfluence
 		},
 	})
 	if err != nil {
-		return nil, err
+		return nil, remoteError(err)
 	}
 	return content, nil
 }
 
 func (c *ConfluenceClient) UpdatePage(page *UpdateConfluencePage) (*goconfluence.Content, error) {
 	content, err := c.apiClient.UpdateContent(&goconfluence.Content{
-		ID:    page.PageID,
-		Type:  "page",
-		Title: page.Title,
+		ID:        page.PageID,
+		Type:      "page",
+		Title:     page.Title,
+		Ancestors: ancestors(page.ParentPageID),
 		Body: goconfluence.Body{
 			Storage: goconfluence.Storage{
 				Value:          page.Body,
@@ -62,17 +105,20 @@ func (c *ConfluenceClient) UpdatePage(page *UpdateConfluencePage) (*goconfluence
 		},
 	})
 	if err != nil {
-		return nil, err
+		return nil, remoteError(err)
 	}
 	return content, nil
 }
 
 func (c *ConfluenceClient) GetPageByID(pageID string) (*goconfluence.Content, error) {
 	content, err := c.apiClient.GetContentByID(pageID, goconfluence.ContentQuery{
-		Expand: []string{"body.storage", "version"},
+		Expand: []string{"body.storage", "version", "ancestors"},
 	})
+	if statusCode(err) == http.StatusNotFound {
+		return nil, ErrPageNotFound
+	}
 	if err != nil {
-		return nil, err
+		return nil, remoteError(err)
 	}
 	return content, nil
 }
@@ -81,20 +127,71 @@ func (c *ConfluenceClient) GetPageByTitle(spaceKey, title string) (*goconfluence
 	contents, err := c.apiClient.GetContent(goconfluence.ContentQuery{
 		SpaceKey: spaceKey,
 		Title:    title,
-		Expand:   []string{"body.storage", "version"},
+		Expand:   []string{"body.storage", "version", "ancestors"},
 	})
 	if err != nil {
-		return nil, err
+		return nil, remoteError(err)
 	}
 	if len(contents.Results) == 0 {
-		return nil, fmt.Errorf("page not found")
+		return nil, ErrPageNotFound
 	}
 	return &contents.Results[0], nil
 }
 
 func (c *ConfluenceClient) DeletePage(pageID string) error {
 	_, err := c.apiClient.DelContent(pageID)
-	return err
+	return remoteError(err)
+}
+
+func (c *ConfluenceClient) GetAttachments(pageID string) ([]PageAttachment, error) {
+	search, err := c.apiClient.GetAttachments(pageID)
+	if err != nil {
+		return nil, remoteError(err)
+	}
+	attachments := make([]PageAttachment, 0, len(search.Results))
+	for _, result := range search.Results {
+		attachments = append(attachments, PageAttachment{ID: result.ID, Filename: result.Title})
+	}
+	return attachments, nil
+}
+
+func (c *ConfluenceClient) UploadAttachment(pageID, filename string, content io.Reader) error {
+	_, err := c.apiClient.UploadAttachment(pageID, filename, content)
+	return remoteError(err)
+}
+
+func (c *ConfluenceClient) UpdateAttachment(pageID, attachmentID, filename string, content io.Reader) error {
+	_, err := c.apiClient.UpdateAttachment(pageID, filename, attachmentID, content)
+	return remoteError(err)
+}
+
+func (c *ConfluenceClient) GetLabels(pageID string) ([]string, error) {
+	result, err := c.apiClient.GetLabels(pageID)
+	if err != nil {
+		return nil, remoteError(err)
+	}
+	labels := make([]string, 0, len(result.Labels))
+	for _, label := range result.Labels {
+		labels = append(labels, label.Name)
+	}
+	return labels, nil
+}
+
+func (c *ConfluenceClient) AddLabels(pageID string, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	request := make([]goconfluence.Label, 0, len(labels))
+	for _, label := range labels {
+		request = append(request, goconfluence.Label{Prefix: "global", Name: label})
+	}
+	_, err := c.apiClient.AddLabels(pageID, &request)
+	return remoteError(err)
+}
+
+func (c *ConfluenceClient) RemoveLabel(pageID, label string) error {
+	_, err := c.apiClient.DeleteLabel(pageID, label)
+	return remoteError(err)
 }
 
 func (c *ConfluenceClient) GetSpacePages(params GetSpacePages) ([]goconfluence.Content, error) {
@@ -105,7 +202,127 @@ func (c *ConfluenceClient) GetSpacePages(params GetSpacePages) ([]goconfluence.C
 		Expand:   []string{"body.storage", "version"},
 	})
 	if err != nil {
-		return nil, err
+		return nil, remoteError(err)
 	}
 	return contents.Results, nil
 }
+
+// FindPagesByLabel returns all pages of a space that carry a label
+func (c *ConfluenceClient) FindPagesByLabel(spaceKey, label string) ([]goconfluence.Content, error) {
+	const limit = 100
+	space, err := cqlString(spaceKey)
+	if err != nil {
+		return nil, err
+	}
+	labelValue, err := cqlString(label)
+	if err != nil {
+		return nil, err
+	}
+	var pages []goconfluence.Content
+	for start := 0; ; start += limit {
+		search, err := c.apiClient.Search(goconfluence.SearchQuery{
+			CQL:   fmt.Sprintf("type = page and space = %s and label = %s", space, labelValue),
+			Limit: limit,
+			Start: start,
+		})
+		if err != nil {
+			return nil, remoteError(err)
+		}
+		for _, result := range search.Results {
+			pages = append(pages, result.Content)
+		}
+		if len(search.Results) < limit {
+			return pages, nil
+		}
+	}
+}
+
+func (c *ConfluenceClient) CurrentUser() (*goconfluence.User, error) {
+	user, err := c.apiClient.CurrentUser()
+	return user, remoteError(err)
+}
+
+func (c *ConfluenceClient) GetSpace(spaceKey string) (*goconfluence.Space, error) {
+	spaces, err := c.apiClient.GetAllSpaces(goconfluence.AllSpacesQuery{
+		SpaceKey: spaceKey,
+		Limit:    1,
+	})
+	if err != nil {
+		return nil, remoteError(err)
+	}
+	if len(spaces.Results) == 0 {
+		return nil, ErrSpaceNotFound
+	}
+	return &spaces.Results[0], nil
+}
+
+// remoteError tells network and authentication failures apart from other
+// failures of the Confluence API
+func remoteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		return errs.Wrap(errs.KindNetwork, err)
+	}
+	switch statusCode(err) {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errs.Wrap(errs.KindAuth, err)
+	}
+	return err
+}
+
+// statusCode returns the HTTP status of a failed API request, 0 when the
+// request failed for another reason. The API client only reports errors as
+// "authentication failed" for 401 and as "<reason>: <status line>" for
+// every other unexpected status.
+func statusCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		return 0
+	}
+	message := err.Error()
+	if message == "authentication failed" {
+		return http.StatusUnauthorized
+	}
+	separator := strings.LastIndex(message, ": ")
+	if separator < 0 {
+		return 0
+	}
+	code, _, _ := strings.Cut(message[separator+2:], " ")
+	number, err := strconv.Atoi(code)
+	if err != nil || number < 100 || number > 599 {
+		return 0
+	}
+	return number
+}
+
+// cqlString quotes a value for a CQL query. Confluence labels and space keys
+// never contain quotes or backslashes, so values that do are rejected rather
+// than escaped.
+func cqlString(value string) (string, error) {
+	if strings.ContainsAny(value, "\"\\") {
+		return "", errs.Validation("'%s' cannot be used in a Confluence search, it contains a quote or a backslash", value)
+	}
+	return `"` + value + `"`, nil
+}
+
+func ancestors(parentPageID string) []goconfluence.Ancestor {
+	if parentPageID == "" {
+		return nil
+	}
+	return []goconfluence.Ancestor{{ID: parentPageID}}
+}
+
+// ParentPageID returns the ID of the direct parent of a page, empty for pages
+// at the space root or when the ancestors were not expanded
+func ParentPageID(page *goconfluence.Content) string {
+	if len(page.Ancestors) == 0 {
+		return ""
+	}
+	return page.Ancestors[len(page.Ancestors)-1].ID
+}
@@ -0,0 +1,71 @@
+package confluence
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Hasankanso/docli/internal/errs"
+)
+
+func TestGetPageByIDNotFound(t *testing.T) {
+	fake := newFakeConfluence(t)
+	// IDs containing 404 must not be mistaken for missing pages
+	page := fake.addPage("Runbook", "<p>Steps</p>", 1)
+	fake.pages["14045"] = page
+
+	_, err := fake.client.GetPageByID("14045")
+	if err != nil {
+		t.Fatalf("GetPageByID of an existing page: %v", err)
+	}
+	_, err = fake.client.GetPageByID("999")
+	if !errors.Is(err, ErrPageNotFound) {
+		t.Fatalf("GetPageByID of a missing page = %v, want ErrPageNotFound", err)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("authentication failed"), http.StatusUnauthorized},
+		{errors.New("unknown response status: 404 Not Found"), http.StatusNotFound},
+		{errors.New("unknown response status: 403 Forbidden"), http.StatusForbidden},
+		{errors.New("internal server error: 500 Internal Server Error"), http.StatusInternalServerError},
+		{errors.New("conflict: 409 Conflict"), http.StatusConflict},
+		{errors.New("invalid character '<' looking for beginning of value"), 0},
+		{errors.New("page 404 is broken"), 0},
+	}
+	for _, test := range tests {
+		if got := statusCode(test.err); got != test.want {
+			t.Errorf("statusCode(%v) = %d, want %d", test.err, got, test.want)
+		}
+	}
+	if kind := errs.KindOf(remoteError(errors.New("unknown response status: 403 Forbidden"))); kind != errs.KindAuth {
+		t.Errorf("403 maps to kind %v, want auth", kind)
+	}
+}
+
+func TestFindPagesByLabelQuotesCQL(t *testing.T) {
+	fake := newFakeConfluence(t)
+	_, err := fake.client.FindPagesByLabel("DOC", ManagedLabel)
+	if err != nil {
+		t.Fatalf("FindPagesByLabel: %v", err)
+	}
+	want := `type = page and space = "DOC" and label = "docli-managed"`
+	if len(fake.queries) != 1 || fake.queries[0] != want {
+		t.Errorf("queries = %q, want %q", fake.queries, want)
+	}
+
+	for _, label := range []string{`x" or label = "y`, `x\`} {
+		_, err = fake.client.FindPagesByLabel("DOC", label)
+		if errs.KindOf(err) != errs.KindValidation {
+			t.Errorf("FindPagesByLabel(%q) = %v, want a validation error", label, err)
+		}
+	}
+	if len(fake.queries) != 1 {
+		t.Errorf("labels with quotes were sent to Confluence: %q", fake.queries[1:])
+	}
+}
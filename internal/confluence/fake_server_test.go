@@ -0,0 +1,218 @@
+package confluence
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+var labelQueryPattern = regexp.MustCompile(`label = "([^"]*)"`)
+
+// fakeConfluence is an in-memory Confluence REST API serving the endpoints
+// docli uses. It records every request it receives.
+type fakeConfluence struct {
+	t      *testing.T
+	server *httptest.Server
+	client *ConfluenceClient
+
+	mu       sync.Mutex
+	nextID   int
+	pages    map[string]*goconfluence.Content
+	labels   map[string][]string
+	requests []string
+	// queries are the CQL queries of the search requests
+	queries []string
+}
+
+func newFakeConfluence(t *testing.T) *fakeConfluence {
+	t.Helper()
+	fake := &fakeConfluence{
+		t:      t,
+		nextID: 1000,
+		pages:  map[string]*goconfluence.Content{},
+		labels: map[string][]string{},
+	}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(fake.server.Close)
+
+	client, err := NewConfluenceClient(fake.server.URL+"/rest/api", "user@example.com", "token")
+	if err != nil {
+		t.Fatalf("NewConfluenceClient: %v", err)
+	}
+	fake.client = client
+	return fake
+}
+
+// addPage stores a page as if it was created on Confluence
+func (f *fakeConfluence) addPage(title, body string, version int) *goconfluence.Content {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	page := &goconfluence.Content{
+		ID:      strconv.Itoa(f.nextID),
+		Type:    "page",
+		Title:   title,
+		Space:   &goconfluence.Space{Key: "DOC"},
+		Body:    goconfluence.Body{Storage: goconfluence.Storage{Value: body, Representation: "storage"}},
+		Version: &goconfluence.Version{Number: version},
+	}
+	f.pages[page.ID] = page
+	return page
+}
+
+// editPage changes a page the way an edit on Confluence does
+func (f *fakeConfluence) editPage(id, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page := f.pages[id]
+	page.Body.Storage.Value = body
+	page.Version.Number++
+}
+
+func (f *fakeConfluence) page(id string) *goconfluence.Content {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pages[id]
+}
+
+// takeRequests returns the requests received since the last call, as
+// "METHOD /path"
+func (f *fakeConfluence) takeRequests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests
+}
+
+func (f *fakeConfluence) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/rest/api")
+	f.requests = append(f.requests, r.Method+" "+path)
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case path == "/content/" && r.Method == http.MethodGet:
+		var results []goconfluence.Content
+		for _, page := range f.pages {
+			if page.Title == r.URL.Query().Get("title") {
+				results = append(results, *page)
+			}
+		}
+		f.reply(w, goconfluence.ContentSearch{Results: results})
+	case path == "/content/" && r.Method == http.MethodPost:
+		var content goconfluence.Content
+		if !f.decode(w, r, &content) {
+			return
+		}
+		f.nextID++
+		content.ID = strconv.Itoa(f.nextID)
+		content.Version = &goconfluence.Version{Number: 1}
+		f.pages[content.ID] = &content
+		f.reply(w, content)
+	case path == "/search" && r.Method == http.MethodGet:
+		query := r.URL.Query().Get("cql")
+		f.queries = append(f.queries, query)
+		var search goconfluence.Search
+		if match := labelQueryPattern.FindStringSubmatch(query); match != nil {
+			for id, labels := range f.labels {
+				if page, found := f.pages[id]; found && slices.Contains(labels, match[1]) {
+					// Search results carry no body
+					search.Results = append(search.Results, goconfluence.Results{
+						Content: goconfluence.Content{ID: page.ID, Type: "page", Title: page.Title},
+					})
+				}
+			}
+		}
+		f.reply(w, search)
+	case len(segments) == 2 && segments[0] == "content":
+		f.serveContent(w, r, segments[1])
+	case len(segments) == 3 && segments[0] == "content" && segments[2] == "label":
+		f.serveLabels(w, r, segments[1])
+	case len(segments) == 4 && segments[0] == "content" && segments[2] == "child" && segments[3] == "attachment":
+		f.reply(w, goconfluence.Search{})
+	default:
+		http.Error(w, "unexpected request", http.StatusNotImplemented)
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	}
+}
+
+func (f *fakeConfluence) serveContent(w http.ResponseWriter, r *http.Request, id string) {
+	page, found := f.pages[id]
+	if !found {
+		http.Error(w, "no content with the given id", http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		f.reply(w, page)
+	case http.MethodPut:
+		var content goconfluence.Content
+		if !f.decode(w, r, &content) {
+			return
+		}
+		// Confluence only accepts the version that follows the current one
+		if content.Version == nil || content.Version.Number != page.Version.Number+1 {
+			http.Error(w, "version must be incremented on update", http.StatusConflict)
+			return
+		}
+		page.Title = content.Title
+		page.Body = content.Body
+		page.Version = &goconfluence.Version{Number: content.Version.Number}
+		if len(content.Ancestors) > 0 {
+			page.Ancestors = content.Ancestors
+		}
+		f.reply(w, page)
+	case http.MethodDelete:
+		delete(f.pages, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeConfluence) serveLabels(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method == http.MethodPost {
+		var labels []goconfluence.Label
+		if !f.decode(w, r, &labels) {
+			return
+		}
+		for _, label := range labels {
+			if !slices.Contains(f.labels[id], label.Name) {
+				f.labels[id] = append(f.labels[id], label.Name)
+			}
+		}
+	}
+	var result goconfluence.Labels
+	for _, name := range f.labels[id] {
+		result.Labels = append(result.Labels, goconfluence.Label{Prefix: "global", Name: name})
+	}
+	f.reply(w, result)
+}
+
+func (f *fakeConfluence) decode(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(value)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (f *fakeConfluence) reply(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		f.t.Errorf("failed to encode reply: %v", err)
+	}
+}
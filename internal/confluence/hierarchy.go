@@ -0,0 +1,136 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+// rootPageBody lists the child pages of the root page
+const rootPageBody = `<p>Project documentation managed by docli.</p>
+<ac:structured-macro ac:name="children" ac:schema-version="2" />`
+
+// hierarchy resolves the parent page of every document during a sync
+type hierarchy struct {
+	rootPageID string
+	// pageIDs maps document IDs to the IDs of their pages, pages that are
+	// only planned have a newPageID placeholder
+	pageIDs map[string]string
+	docMeta map[string]spec.DocMetaData
+}
+
+func newHierarchy(docMetaList []spec.DocMetaData, rootPageID string) *hierarchy {
+	h := &hierarchy{
+		rootPageID: rootPageID,
+		pageIDs:    map[string]string{},
+		docMeta:    map[string]spec.DocMetaData{},
+	}
+	for _, docMeta := range docMetaList {
+		h.docMeta[docMeta.ID] = docMeta
+		if docMeta.Targets != nil && docMeta.Targets.Confluence != nil && docMeta.Targets.Confluence.PageID != "" {
+			h.pageIDs[docMeta.ID] = docMeta.Targets.Confluence.PageID
+		}
+	}
+	return h
+}
+
+// parentPageID returns the page a document belongs under: the page of its
+// parent document, the Confluence page it names, or the root page. It is
+// empty when the document belongs at the space root.
+func (h *hierarchy) parentPageID(docMeta spec.DocMetaData) (string, error) {
+	if docMeta.Parent == "" {
+		return h.rootPageID, nil
+	}
+	if parent, found := h.docMeta[docMeta.Parent]; found {
+		pageID, found := h.pageIDs[parent.ID]
+		if !found {
+			return "", fmt.Errorf("parent document '%s' has no Confluence page yet", parent.Name)
+		}
+		return pageID, nil
+	}
+	if strings.IndexFunc(docMeta.Parent, func(char rune) bool { return !unicode.IsDigit(char) }) == -1 {
+		return docMeta.Parent, nil
+	}
+	return "", fmt.Errorf("parent '%s' is neither a document ID nor a Confluence page ID", docMeta.Parent)
+}
+
+// record remembers the page of a document once it was synced or planned
+func (h *hierarchy) record(docID, pageID string) {
+	if pageID != "" {
+		h.pageIDs[docID] = pageID
+	}
+}
+
+// newPageID stands in for the ID of a page that is only planned
+func newPageID(title string) string {
+	return "new:" + title
+}
+
+// describePage names a page for messages and plans
+func describePage(pageID string) string {
+	if title, planned := strings.CutPrefix(pageID, "new:"); planned {
+		return fmt.Sprintf("new page '%s'", title)
+	}
+	return "page " + pageID
+}
+
+// errParentCycle is reported for documents whose parents form a cycle
+var errParentCycle = errors.New("the parents of this document form a cycle")
+
+// orderByParent sorts documents so that every document comes after its
+// parent document, otherwise keeping the order of the spec. Documents whose
+// parents form a cycle are returned separately.
+func orderByParent(docMetaList []spec.DocMetaData) (ordered, cyclic []spec.DocMetaData) {
+	byID := map[string]spec.DocMetaData{}
+	for _, docMeta := range docMetaList {
+		byID[docMeta.ID] = docMeta
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	inCycle := map[string]bool{}
+	var path []string
+	var visit func(docMeta spec.DocMetaData)
+	visit = func(docMeta spec.DocMetaData) {
+		switch state[docMeta.ID] {
+		case visiting:
+			// Every document on the path since this one is part of the cycle
+			for i := len(path) - 1; i >= 0; i-- {
+				inCycle[path[i]] = true
+				if path[i] == docMeta.ID {
+					break
+				}
+			}
+			return
+		case done:
+			return
+		}
+
+		state[docMeta.ID] = visiting
+		path = append(path, docMeta.ID)
+		if parent, found := byID[docMeta.Parent]; found {
+			visit(parent)
+		}
+		path = path[:len(path)-1]
+		state[docMeta.ID] = done
+		if !inCycle[docMeta.ID] {
+			ordered = append(ordered, docMeta)
+		}
+	}
+
+	for _, docMeta := range docMetaList {
+		visit(docMeta)
+	}
+	for _, docMeta := range docMetaList {
+		if inCycle[docMeta.ID] {
+			cyclic = append(cyclic, docMeta)
+		}
+	}
+	return ordered, cyclic
+}
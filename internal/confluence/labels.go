@@ -0,0 +1,82 @@
+package confluence
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/converter"
+)
+
+// ManagedLabel marks the pages docli created, so that pages whose document
+// was deleted can be found and pruned
+const ManagedLabel = "docli-managed"
+
+// planLabels decides which labels to add to and remove from a page. Labels
+// of the document missing on the page are added, labels docli added before
+// that the document no longer has are removed. Labels added on Confluence
+// are never removed.
+func (cmd *SyncConfluenceCommand) planLabels(step *syncStep) error {
+	docMeta := step.result.DocMeta
+	if len(docMeta.Labels) == 0 && len(step.target.Labels) == 0 {
+		return nil
+	}
+
+	remote, err := cmd.Client.GetLabels(step.page.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list labels of page %s: %w", step.page.ID, err)
+	}
+	for _, label := range docMeta.Labels {
+		if !slices.Contains(remote, label) {
+			step.addLabels = append(step.addLabels, label)
+		}
+	}
+	for _, label := range step.target.Labels {
+		if !docMeta.HasLabel(label) && slices.Contains(remote, label) {
+			step.removeLabels = append(step.removeLabels, label)
+		}
+	}
+	return nil
+}
+
+func (step *syncStep) labelsChanged() bool {
+	return len(step.addLabels) > 0 || len(step.removeLabels) > 0
+}
+
+func (step *syncStep) noteLabels() {
+	if len(step.addLabels) > 0 {
+		step.note("add labels " + strings.Join(step.addLabels, ", "))
+	}
+	if len(step.removeLabels) > 0 {
+		step.note("remove labels " + strings.Join(step.removeLabels, ", "))
+	}
+}
+
+// applyLabels updates the labels of a page and remembers the labels docli
+// added in the document's mapping
+func (cmd *SyncConfluenceCommand) applyLabels(step *syncStep, pageID string) error {
+	labels := step.addLabels
+	if step.page == nil {
+		labels = append([]string{ManagedLabel}, labels...)
+	}
+	err := cmd.Client.AddLabels(pageID, labels)
+	if err != nil {
+		return fmt.Errorf("failed to add labels to page %s: %w", pageID, err)
+	}
+	for _, label := range step.removeLabels {
+		err := cmd.Client.RemoveLabel(pageID, label)
+		if err != nil {
+			return fmt.Errorf("failed to remove label %s from page %s: %w", label, pageID, err)
+		}
+	}
+	step.target.Labels = step.result.DocMeta.Labels
+	return nil
+}
+
+// storageBody converts markdown to the page body, with the document's
+// properties in front of it
+func (step *syncStep) storageBody(markdown string) string {
+	docMeta := step.result.DocMeta
+	return converter.PropertiesToStorage(docMeta.PropertyKeys(), docMeta.Properties) +
+		converter.MarkdownToStorage(markdown, step.options.storage)
+}
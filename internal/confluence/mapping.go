@@ -0,0 +1,86 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/spec"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+// snapshotKind groups the markdown snapshots of synced Confluence pages
+const snapshotKind = "confluence"
+
+// findPage looks up the page of a document by its mapped page ID, falling
+// back to a title lookup when the document is not mapped yet or the mapped
+// page no longer exists. The returned target is a copy of the document's
+// mapping with the space key filled in.
+func findPage(client *ConfluenceClient, docMeta spec.DocMetaData, defaultSpaceKey string) (*goconfluence.Content, *spec.ConfluenceTarget, error) {
+	target := &spec.ConfluenceTarget{}
+	if docMeta.Targets != nil && docMeta.Targets.Confluence != nil {
+		*target = *docMeta.Targets.Confluence
+	}
+	if target.SpaceKey == "" {
+		target.SpaceKey = defaultSpaceKey
+	}
+
+	if target.PageID != "" {
+		page, err := client.GetPageByID(target.PageID)
+		if !errors.Is(err, ErrPageNotFound) {
+			return page, target, err
+		}
+		// The mapped page is gone, whatever was recorded about it is stale
+		target = &spec.ConfluenceTarget{SpaceKey: target.SpaceKey, ParentPageID: target.ParentPageID}
+	}
+
+	page, err := client.GetPageByTitle(target.SpaceKey, docMeta.Name)
+	return page, target, err
+}
+
+// recordSync stores the page a document was synced with and its parent page
+// in spec.json, along with the hash and a snapshot of the markdown that now
+// matches the page. The snapshot is the common base for later conflict
+// detection and merges.
+func recordSync(specRepo *spec.SpecRepo, result SyncResult, target *spec.ConfluenceTarget, page *goconfluence.Content, parentPageID, markdown string) SyncResult {
+	hash := spec.ContentHash(markdown)
+	alreadyRecorded := target.PageID == page.ID && target.ParentPageID == parentPageID &&
+		target.LastSyncedVersion == pageVersion(page) && target.LastSyncedHash == hash &&
+		slices.Equal(target.Labels, recordedLabels(result.DocMeta))
+	if result.Status == SyncUnchanged && alreadyRecorded {
+		// Avoid rewriting spec.json when nothing happened
+		return result
+	}
+
+	target.PageID = page.ID
+	target.ParentPageID = parentPageID
+	target.LastSyncedVersion = pageVersion(page)
+	target.LastSyncedHash = hash
+	target.LastSyncedAt = time.Now().UTC()
+
+	err := specRepo.WriteSnapshot(snapshotKind, result.DocMeta.ID, markdown)
+	if err == nil {
+		err = specRepo.SetConfluenceTarget(result.DocMeta.ID, target)
+	}
+	if err != nil {
+		result.Status = SyncFailed
+		result.Err = fmt.Errorf("page %s was synced but its mapping could not be saved: %w", page.ID, err)
+	}
+	return result
+}
+
+// recordedLabels returns the labels spec.json says docli added to the page
+func recordedLabels(docMeta spec.DocMetaData) []string {
+	if docMeta.Targets == nil || docMeta.Targets.Confluence == nil {
+		return nil
+	}
+	return docMeta.Targets.Confluence.Labels
+}
+
+func pageVersion(page *goconfluence.Content) int {
+	if page.Version == nil {
+		return 0
+	}
+	return page.Version.Number
+}
@@ -0,0 +1,167 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Hasankanso/docli/internal/converter"
+	"github.com/Hasankanso/docli/internal/diff"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/plan"
+	"github.com/Hasankanso/docli/internal/spec"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+type PruneConfluenceCommand struct {
+	SpecRepo *spec.SpecRepo
+	Client   *ConfluenceClient
+	SpaceKey string
+	DryRun   bool
+	// Confirm is asked before the orphaned pages are deleted; a nil Confirm
+	// deletes without asking
+	Confirm func(pages []goconfluence.Content) bool
+}
+
+func NewPruneConfluenceCommand(NewSpecRepo *spec.SpecRepo, client *ConfluenceClient, spaceKey string, dryRun bool, confirm func(pages []goconfluence.Content) bool) *PruneConfluenceCommand {
+	return &PruneConfluenceCommand{
+		SpecRepo: NewSpecRepo,
+		Client:   client,
+		SpaceKey: spaceKey,
+		DryRun:   dryRun,
+		Confirm:  confirm,
+	}
+}
+
+func (cmd *PruneConfluenceCommand) Run() error {
+	specExists := cmd.SpecRepo.SpecExists()
+	if !specExists {
+		return errs.SpecNotFound()
+	}
+
+	docSpec, err := cmd.SpecRepo.GetSpec()
+	if err != nil {
+		return fmt.Errorf("error reading documentation configuration: %w", err)
+	}
+
+	managed, err := cmd.Client.FindPagesByLabel(cmd.SpaceKey, ManagedLabel)
+	if err != nil {
+		return fmt.Errorf("failed to search space '%s' for pages created by docli: %w", cmd.SpaceKey, err)
+	}
+	orphans := orphanedPages(docSpec, managed)
+	if len(orphans) == 0 {
+		logger.Success("No orphaned pages found in Confluence space '%s'", cmd.SpaceKey)
+		return nil
+	}
+
+	logger.Info("Found %d page(s) in Confluence space '%s' that no longer belong to a document:", len(orphans), cmd.SpaceKey)
+	for _, page := range orphans {
+		logger.Info("  %s (page %s)", page.Title, page.ID)
+	}
+	if cmd.DryRun {
+		logger.Info("Dry run, no pages were deleted")
+		return nil
+	}
+	if cmd.Confirm != nil && !cmd.Confirm(orphans) {
+		logger.Info("Prune cancelled")
+		return nil
+	}
+
+	var failures []error
+	for _, page := range orphans {
+		err := cmd.Client.DeletePage(page.ID)
+		if err != nil {
+			logger.Error("Failed to delete page %s (%s): %v", page.ID, page.Title, err)
+			failures = append(failures, err)
+			continue
+		}
+		logger.Info("deleted    %s (page %s)", page.Title, page.ID)
+	}
+	if len(failures) > 0 {
+		return errs.Wrap(failureKind(failures), fmt.Errorf("prune finished with %d failure(s)", len(failures)))
+	}
+	logger.Success("Deleted %d orphaned page(s)", len(orphans))
+	return nil
+}
+
+// orphanedPages returns the pages docli created that are neither the root
+// page nor mapped to a document
+func orphanedPages(docSpec *spec.DocSpec, managed []goconfluence.Content) []goconfluence.Content {
+	mapped := map[string]bool{}
+	if docSpec.Confluence != nil && docSpec.Confluence.RootPageID != "" {
+		mapped[docSpec.Confluence.RootPageID] = true
+	}
+	for _, docMeta := range docSpec.DocMeta {
+		if docMeta.Targets != nil && docMeta.Targets.Confluence != nil && docMeta.Targets.Confluence.PageID != "" {
+			mapped[docMeta.Targets.Confluence.PageID] = true
+		}
+	}
+
+	var orphans []goconfluence.Content
+	for _, page := range managed {
+		if !mapped[page.ID] {
+			orphans = append(orphans, page)
+		}
+	}
+	return orphans
+}
+
+// planDeletions lists the orphaned pages 'docli prune' deletes, with a diff of
+// their body against nothing
+func planDeletions(client *ConfluenceClient, docSpec *spec.DocSpec, spaceKey string, options *linkOptions) ([]plan.Action, error) {
+	managed, err := client.FindPagesByLabel(spaceKey, ManagedLabel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search space '%s' for pages created by docli: %w", spaceKey, err)
+	}
+
+	var actions []plan.Action
+	for _, orphan := range orphanedPages(docSpec, managed) {
+		action := plan.Action{
+			Action: plan.ActionDelete,
+			Title:  orphan.Title,
+			PageID: orphan.ID,
+			Detail: "no longer belongs to a document, run 'docli prune' to delete it",
+		}
+		// Search results come without a body
+		page, err := client.GetPageByID(orphan.ID)
+		if errors.Is(err, ErrPageNotFound) {
+			continue
+		}
+		if err != nil {
+			action.Action = plan.ActionError
+			action.Detail = fmt.Sprintf("failed to look up orphaned page: %v", err)
+			actions = append(actions, action)
+			continue
+		}
+		markdown, err := converter.StorageToMarkdown(page.Body.Storage.Value, options.markdown)
+		if err != nil {
+			markdown = page.Body.Storage.Value
+		}
+		action.Diff = diff.Unified(fmt.Sprintf("confluence:%s", page.Title), "/dev/null", markdown, "", 3)
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// DeletePage deletes the page a document is synced with, along with the
+// snapshot of its last sync. Documents that were never synced are left alone.
+func DeletePage(specRepo *spec.SpecRepo, client *ConfluenceClient, docMeta *spec.DocMetaData) (string, error) {
+	if docMeta.Targets == nil || docMeta.Targets.Confluence == nil || docMeta.Targets.Confluence.PageID == "" {
+		return "", nil
+	}
+	pageID := docMeta.Targets.Confluence.PageID
+
+	_, err := client.GetPageByID(pageID)
+	if errors.Is(err, ErrPageNotFound) {
+		// Already deleted on Confluence
+		return "", specRepo.RemoveSnapshot(snapshotKind, docMeta.ID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up page %s: %w", pageID, err)
+	}
+	err = client.DeletePage(pageID)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete page %s: %w", pageID, err)
+	}
+	return pageID, specRepo.RemoveSnapshot(snapshotKind, docMeta.ID)
+}
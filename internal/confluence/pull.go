@@ -0,0 +1,130 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/converter"
+	"github.com/Hasankanso/docli/internal/diff"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+type PullConfluenceCommand struct {
+	SpecRepo *spec.SpecRepo
+	Client   *ConfluenceClient
+	SpaceKey string
+	// Confirm is asked before a local file is overwritten; a nil Confirm
+	// overwrites without asking
+	Confirm func(docMeta spec.DocMetaData) bool
+}
+
+func NewPullConfluenceCommand(NewSpecRepo *spec.SpecRepo, client *ConfluenceClient, spaceKey string, confirm func(docMeta spec.DocMetaData) bool) *PullConfluenceCommand {
+	return &PullConfluenceCommand{
+		SpecRepo: NewSpecRepo,
+		Client:   client,
+		SpaceKey: spaceKey,
+		Confirm:  confirm,
+	}
+}
+
+func (cmd *PullConfluenceCommand) Run() error {
+	specExists := cmd.SpecRepo.SpecExists()
+	if !specExists {
+		return errs.SpecNotFound()
+	}
+
+	docSpec, err := cmd.SpecRepo.GetSpec()
+	if err != nil {
+		return fmt.Errorf("error reading documentation configuration: %w", err)
+	}
+
+	if !slices.Contains(docSpec.Platforms, "confluence") {
+		return errs.Validation("confluence is not a configured platform in %s", cmd.SpecRepo.SpecJsonFilePath)
+	}
+
+	if len(docSpec.DocMeta) == 0 {
+		logger.Info("No document metadata entries found, nothing to pull")
+		return nil
+	}
+
+	logger.Info("Pulling %d document(s) from Confluence space '%s'", len(docSpec.DocMeta), cmd.SpaceKey)
+
+	options := newLinkOptions(docSpec.DocMeta)
+	results := make([]SyncResult, 0, len(docSpec.DocMeta))
+	for _, docMeta := range docSpec.DocMeta {
+		start := time.Now()
+		result := cmd.pullDocument(docMeta, options)
+		result.Duration = time.Since(start)
+		results = append(results, result)
+	}
+
+	return printSyncSummary("Confluence pull", results)
+}
+
+func (cmd *PullConfluenceCommand) pullDocument(docMeta spec.DocMetaData, options *linkOptions) SyncResult {
+	result := SyncResult{DocMeta: docMeta}
+
+	page, target, err := findPage(cmd.Client, docMeta, cmd.SpaceKey)
+	if errors.Is(err, ErrPageNotFound) {
+		result.Status = SyncMissing
+		result.Detail = "no Confluence page found, run 'docli sync confluence' first"
+		return result
+	}
+	if err != nil {
+		result.Status = SyncFailed
+		result.Err = fmt.Errorf("failed to look up page: %w", err)
+		return result
+	}
+	result.PageID = page.ID
+
+	docPath := cmd.SpecRepo.DocFilePath(&docMeta)
+	local, err := os.ReadFile(docPath)
+	if err != nil && !os.IsNotExist(err) {
+		result.Status = SyncFailed
+		result.Err = err
+		return result
+	}
+
+	// Attachments map back to the local files the document references
+	attachments, err := collectAttachments(cmd.SpecRepo.ProjectDir(), docPath, string(local), options)
+	if err == nil {
+		options = options.withAttachments(attachments)
+	}
+	remote, err := converter.StorageToMarkdown(page.Body.Storage.Value, options.markdown)
+	if err != nil {
+		result.Status = SyncFailed
+		result.Err = err
+		return result
+	}
+
+	changes := diff.Unified(docPath+" (local)", docPath+" (confluence)", string(local), remote, 3)
+	if changes == "" {
+		result.Status = SyncUnchanged
+		return recordSync(cmd.SpecRepo, result, target, page, ParentPageID(page), remote)
+	}
+
+	fmt.Print(changes)
+	if cmd.Confirm != nil && !cmd.Confirm(docMeta) {
+		result.Status = SyncSkipped
+		result.Detail = "local file kept"
+		return result
+	}
+
+	err = os.MkdirAll(filepath.Dir(docPath), 0755)
+	if err == nil {
+		err = os.WriteFile(docPath, []byte(remote), 0644)
+	}
+	if err != nil {
+		result.Status = SyncFailed
+		result.Err = fmt.Errorf("failed to write %s: %w", docPath, err)
+		return result
+	}
+	result.Status = SyncPulled
+	return recordSync(cmd.SpecRepo, result, target, page, ParentPageID(page), remote)
+}
@@ -0,0 +1,736 @@
+package confluence
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/converter"
+	"github.com/Hasankanso/docli/internal/diff"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/plan"
+	"github.com/Hasankanso/docli/internal/spec"
+	goconfluence "github.com/virtomize/confluence-go-api"
+)
+
+// Statuses reported per document by sync and pull
+const (
+	SyncCreated   = "created"
+	SyncUpdated   = "updated"
+	SyncPulled    = "pulled"
+	SyncUnchanged = "unchanged"
+	SyncSkipped   = "skipped"
+	SyncMerged    = "merged"
+	SyncMoved     = "moved"
+	SyncOutdated  = "outdated"
+	SyncMissing   = "missing"
+	SyncConflict  = "conflict"
+	SyncFailed    = "failed"
+)
+
+// syncStatuses lists the statuses in the order they are summarised
+var syncStatuses = []string{SyncCreated, SyncUpdated, SyncPulled, SyncMerged, SyncMoved, SyncUnchanged, SyncSkipped, SyncOutdated, SyncMissing, SyncConflict, SyncFailed}
+
+// Ways to resolve documents that changed both locally and on Confluence
+const (
+	ResolveNone   = ""
+	ResolveLocal  = "local"
+	ResolveRemote = "remote"
+	ResolveMerge  = "merge"
+)
+
+// SyncResult describes what happened to a single document during a sync
+type SyncResult struct {
+	DocMeta spec.DocMetaData
+	Status  string
+	PageID  string
+	Detail  string
+	Err     error
+	// Duration is how long syncing the document took
+	Duration time.Duration
+}
+
+type SyncConfluenceCommand struct {
+	SpecRepo   *spec.SpecRepo
+	Client     *ConfluenceClient
+	SpaceKey   string
+	Resolution string
+	// RootPage is the title of a page to publish all documents under. It
+	// replaces the root page recorded in spec.json, empty keeps it.
+	RootPage string
+}
+
+func NewSyncConfluenceCommand(NewSpecRepo *spec.SpecRepo, client *ConfluenceClient, spaceKey, resolution, rootPage string) *SyncConfluenceCommand {
+	return &SyncConfluenceCommand{
+		SpecRepo:   NewSpecRepo,
+		Client:     client,
+		SpaceKey:   spaceKey,
+		Resolution: resolution,
+		RootPage:   rootPage,
+	}
+}
+
+func (cmd *SyncConfluenceCommand) Run() error {
+	specExists := cmd.SpecRepo.SpecExists()
+	if !specExists {
+		return errs.SpecNotFound()
+	}
+
+	docSpec, err := cmd.SpecRepo.GetSpec()
+	if err != nil {
+		return fmt.Errorf("error reading documentation configuration: %w", err)
+	}
+
+	if !slices.Contains(docSpec.Platforms, "confluence") {
+		return errs.Validation("confluence is not a configured platform in %s", cmd.SpecRepo.SpecJsonFilePath)
+	}
+
+	if len(docSpec.DocMeta) == 0 {
+		logger.Info("No document metadata entries found, nothing to sync")
+		return nil
+	}
+
+	logger.Info("Syncing %d document(s) to Confluence space '%s'", len(docSpec.DocMeta), cmd.SpaceKey)
+
+	rootPageID, err := cmd.ensureRootPage(docSpec)
+	if err != nil {
+		return err
+	}
+
+	options := newLinkOptions(docSpec.DocMeta)
+	pages := newHierarchy(docSpec.DocMeta, rootPageID)
+	ordered, cyclic := orderByParent(docSpec.DocMeta)
+	results := make([]SyncResult, 0, len(docSpec.DocMeta))
+	for _, docMeta := range ordered {
+		start := time.Now()
+		result := cmd.apply(cmd.planDocument(docMeta, pages, options))
+		result.Duration = time.Since(start)
+		pages.record(docMeta.ID, result.PageID)
+		results = append(results, result)
+	}
+	for _, docMeta := range cyclic {
+		results = append(results, SyncResult{DocMeta: docMeta, Status: SyncFailed, Err: errParentCycle})
+	}
+
+	return printSyncSummary("Confluence sync", results)
+}
+
+// Plan works out what Run would do without changing any page, local file or
+// mapping
+func (cmd *SyncConfluenceCommand) Plan() (*plan.Plan, error) {
+	if !cmd.SpecRepo.SpecExists() {
+		return nil, errs.SpecNotFound()
+	}
+
+	docSpec, err := cmd.SpecRepo.GetSpec()
+	if err != nil {
+		return nil, fmt.Errorf("error reading documentation configuration: %w", err)
+	}
+
+	if !slices.Contains(docSpec.Platforms, "confluence") {
+		return nil, errs.Validation("confluence is not a configured platform in %s", cmd.SpecRepo.SpecJsonFilePath)
+	}
+
+	syncPlan := &plan.Plan{Platform: "confluence", SpaceKey: cmd.SpaceKey, Actions: []plan.Action{}}
+	title, root, err := cmd.findRootPage(docSpec)
+	rootPageID := ""
+	switch {
+	case title == "":
+	case errors.Is(err, ErrPageNotFound):
+		rootPageID = newPageID(title)
+		syncPlan.Actions = append(syncPlan.Actions, plan.Action{Action: plan.ActionCreate, Title: title, Detail: "root page"})
+	case err != nil:
+		return nil, fmt.Errorf("failed to look up root page '%s': %w", title, err)
+	default:
+		rootPageID = root.ID
+		syncPlan.Actions = append(syncPlan.Actions, plan.Action{Action: plan.ActionNone, Title: title, PageID: root.ID, Detail: "root page"})
+	}
+
+	options := newLinkOptions(docSpec.DocMeta)
+	pages := newHierarchy(docSpec.DocMeta, rootPageID)
+	ordered, cyclic := orderByParent(docSpec.DocMeta)
+	for _, docMeta := range ordered {
+		step := cmd.planDocument(docMeta, pages, options)
+		if step.result.Status == SyncCreated {
+			pages.record(docMeta.ID, newPageID(docMeta.Name))
+		} else {
+			pages.record(docMeta.ID, step.result.PageID)
+		}
+		syncPlan.Actions = append(syncPlan.Actions, step.action())
+	}
+	for _, docMeta := range cyclic {
+		step := &syncStep{result: SyncResult{DocMeta: docMeta}}
+		syncPlan.Actions = append(syncPlan.Actions, step.fail(errParentCycle).action())
+	}
+
+	deletions, err := planDeletions(cmd.Client, docSpec, cmd.SpaceKey, options)
+	if err != nil {
+		return nil, err
+	}
+	syncPlan.Actions = append(syncPlan.Actions, deletions...)
+	return syncPlan, nil
+}
+
+// findRootPage looks up the root page of the spec. The title is empty when no
+// root page is configured, and ErrPageNotFound is returned when it does not
+// exist yet.
+func (cmd *SyncConfluenceCommand) findRootPage(docSpec *spec.DocSpec) (string, *goconfluence.Content, error) {
+	title, pageID := cmd.RootPage, ""
+	if docSpec.Confluence != nil {
+		if title == "" {
+			title = docSpec.Confluence.RootPageTitle
+		}
+		if title == docSpec.Confluence.RootPageTitle {
+			pageID = docSpec.Confluence.RootPageID
+		}
+	}
+	if title == "" {
+		return "", nil, nil
+	}
+
+	if pageID != "" {
+		page, err := cmd.Client.GetPageByID(pageID)
+		if !errors.Is(err, ErrPageNotFound) {
+			return title, page, err
+		}
+	}
+	page, err := cmd.Client.GetPageByTitle(cmd.SpaceKey, title)
+	return title, page, err
+}
+
+// ensureRootPage creates the root page of the spec when it is missing and
+// records it in spec.json. It returns an empty ID when no root page is
+// configured.
+func (cmd *SyncConfluenceCommand) ensureRootPage(docSpec *spec.DocSpec) (string, error) {
+	title, page, err := cmd.findRootPage(docSpec)
+	if title == "" {
+		return "", nil
+	}
+	if errors.Is(err, ErrPageNotFound) {
+		page, err = cmd.Client.CreatePage(&CreateConfluencePage{
+			Title:    title,
+			SpaceKey: cmd.SpaceKey,
+			Body:     rootPageBody,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create root page '%s': %w", title, err)
+		}
+		err = cmd.Client.AddLabels(page.ID, []string{ManagedLabel})
+		if err != nil {
+			return "", fmt.Errorf("failed to label root page '%s': %w", title, err)
+		}
+		logger.Info("%-10s %s (root page %s)", SyncCreated, title, page.ID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up root page '%s': %w", title, err)
+	}
+
+	if docSpec.Confluence == nil || docSpec.Confluence.RootPageTitle != title || docSpec.Confluence.RootPageID != page.ID {
+		err = cmd.SpecRepo.SetConfluenceRootPage(title, page.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to record root page '%s': %w", title, err)
+		}
+	}
+	return page.ID, nil
+}
+
+// planActions maps the planned status of a document to its plan action
+var planActions = map[string]string{
+	SyncCreated:   plan.ActionCreate,
+	SyncUpdated:   plan.ActionUpdate,
+	SyncPulled:    plan.ActionPull,
+	SyncMerged:    plan.ActionMerge,
+	SyncMoved:     plan.ActionMove,
+	SyncUnchanged: plan.ActionNone,
+	SyncOutdated:  plan.ActionOutdated,
+	SyncConflict:  plan.ActionConflict,
+	SyncMissing:   plan.ActionMissing,
+	SyncFailed:    plan.ActionError,
+}
+
+// syncStep is the planned sync of a single document along with everything
+// needed to carry it out. The status of its result is the planned status.
+type syncStep struct {
+	result  SyncResult
+	target  *spec.ConfluenceTarget
+	page    *goconfluence.Content
+	docPath string
+	// local is the markdown file and remote the page converted to markdown
+	local  string
+	remote string
+	// content is the markdown the page and the local file hold after the sync
+	content string
+	// parentPageID is the page the document belongs under, move is set when
+	// the page has to be moved there
+	parentPageID string
+	move         bool
+	// rename is set when the page title differs from the document name
+	rename bool
+	// options resolve links and the attachments of this document
+	options     *linkOptions
+	attachments []localAttachment
+	uploads     []localAttachment
+	// addLabels and removeLabels are the label changes on the page
+	addLabels    []string
+	removeLabels []string
+	// bodyChanged is set when the page body has to be replaced
+	bodyChanged bool
+}
+
+// planDocument decides how a document is synced. It only reads from
+// Confluence and the local files.
+func (cmd *SyncConfluenceCommand) planDocument(docMeta spec.DocMetaData, pages *hierarchy, options *linkOptions) *syncStep {
+	step := &syncStep{result: SyncResult{DocMeta: docMeta}}
+	step.docPath = cmd.SpecRepo.DocFilePath(&docMeta)
+	defer func() {
+		logger.With(resultFields(step.result)).Debug("Planned %s for '%s' (%s)", step.result.Status, docMeta.Name, step.docPath)
+	}()
+
+	parentPageID, err := pages.parentPageID(docMeta)
+	if err != nil {
+		return step.fail(err)
+	}
+	step.parentPageID = parentPageID
+
+	content, err := os.ReadFile(step.docPath)
+	if err != nil {
+		step.result.Status = SyncMissing
+		step.result.Detail = "no generated file found, run the updateDoc prompt first"
+		if !os.IsNotExist(err) {
+			step.fail(err)
+		}
+		return step
+	}
+	step.local = string(content)
+	step.content = step.local
+
+	step.attachments, err = collectAttachments(cmd.SpecRepo.ProjectDir(), step.docPath, step.local, options)
+	if err != nil {
+		return step.fail(err)
+	}
+	step.options = options.withAttachments(step.attachments)
+
+	step.page, step.target, err = findPage(cmd.Client, docMeta, cmd.SpaceKey)
+	if errors.Is(err, ErrPageNotFound) {
+		step.result.Status = SyncCreated
+		if step.parentPageID != "" {
+			step.note("under " + describePage(step.parentPageID))
+		}
+		step.uploads = pendingUploads(step.attachments, nil, nil)
+		step.noteUploads()
+		step.addLabels = docMeta.Labels
+		step.noteLabels()
+		return step
+	}
+	if err != nil {
+		return step.fail(fmt.Errorf("failed to look up page: %w", err))
+	}
+	step.result.PageID = step.page.ID
+	if step.parentPageID != "" && step.parentPageID != ParentPageID(step.page) {
+		step.move = true
+		step.note("moved under " + describePage(step.parentPageID))
+	}
+	if step.page.Title != docMeta.Name {
+		step.rename = true
+		step.note(fmt.Sprintf("renamed from '%s'", step.page.Title))
+	}
+
+	if len(step.attachments) > 0 {
+		existing, err := cmd.Client.GetAttachments(step.page.ID)
+		if err != nil {
+			return step.fail(fmt.Errorf("failed to list attachments of page %s: %w", step.page.ID, err))
+		}
+		step.uploads = pendingUploads(step.attachments, step.target.Attachments, existing)
+	}
+	err = cmd.planLabels(step)
+	if err != nil {
+		return step.fail(err)
+	}
+
+	step.remote, err = converter.StorageToMarkdown(step.page.Body.Storage.Value, step.options.markdown)
+	if err != nil {
+		return step.fail(err)
+	}
+
+	if strings.TrimSpace(step.page.Body.Storage.Value) == strings.TrimSpace(step.storageBody(step.local)) {
+		switch {
+		case len(step.uploads) > 0 || step.labelsChanged() || step.rename:
+			step.result.Status = SyncUpdated
+			step.noteUploads()
+			step.noteLabels()
+		case step.move:
+			step.result.Status = SyncMoved
+		default:
+			step.result.Status = SyncUnchanged
+		}
+		return step
+	}
+	step.bodyChanged = true
+
+	localChanged := step.target.LastSyncedHash == "" || step.target.LastSyncedHash != spec.ContentHash(step.local)
+	remoteChanged := step.target.LastSyncedVersion == 0 || step.target.LastSyncedVersion != pageVersion(step.page)
+
+	switch {
+	case !remoteChanged || cmd.Resolution == ResolveLocal:
+		step.result.Status = SyncUpdated
+		step.noteUploads()
+		step.noteLabels()
+		return step
+	case cmd.Resolution == ResolveRemote:
+		step.result.Status = SyncPulled
+		step.content = step.remote
+		return step
+	case !localChanged:
+		step.result.Status = SyncOutdated
+		step.result.Detail = fmt.Sprintf("page changed on Confluence since the last sync (version %d, now %d); "+
+			"run 'docli pull confluence' first or use --force-local to overwrite it", step.target.LastSyncedVersion, pageVersion(step.page))
+		return step
+	case cmd.Resolution == ResolveMerge:
+		return cmd.planMerge(step)
+	}
+
+	step.result.Status = SyncConflict
+	step.result.Detail = conflictDetail(step.target, step.page)
+	return step
+}
+
+// planMerge three-way merges both sides against the snapshot of the last
+// sync. A clean merge is written locally and pushed, anything else is left
+// untouched and reported as a conflict.
+func (cmd *SyncConfluenceCommand) planMerge(step *syncStep) *syncStep {
+	base, found, err := cmd.SpecRepo.ReadSnapshot(snapshotKind, step.result.DocMeta.ID)
+	if err != nil {
+		return step.fail(err)
+	}
+	if !found {
+		step.result.Status = SyncConflict
+		step.result.Detail = conflictDetail(step.target, step.page) + "; no snapshot of the last sync is available to merge against"
+		return step
+	}
+
+	// Compare all three sides in the markdown flavour produced from storage
+	// format, so that formatting differences do not show up as edits
+	base, err = roundTrip(base, step.options)
+	if err != nil {
+		return step.fail(err)
+	}
+	local, err := roundTrip(step.local, step.options)
+	if err != nil {
+		return step.fail(err)
+	}
+
+	merged, conflicts := diff.Merge3(base, local, step.remote, diff.MergeLabels{Ours: "local", Theirs: "confluence"})
+	if conflicts > 0 {
+		step.result.Status = SyncConflict
+		step.result.Detail = fmt.Sprintf("%s; the merge has %d conflicting section(s)", conflictDetail(step.target, step.page), conflicts)
+		return step
+	}
+	step.result.Status = SyncMerged
+	step.content = merged
+	step.noteUploads()
+	step.noteLabels()
+	return step
+}
+
+// action describes the step in a plan, with a diff of every body it changes
+func (step *syncStep) action() plan.Action {
+	result := step.result
+	action := plan.Action{
+		Action: planActions[result.Status],
+		DocID:  result.DocMeta.ID,
+		Title:  result.DocMeta.Name,
+		PageID: result.PageID,
+		Detail: result.Detail,
+	}
+	if result.Err != nil {
+		action.Detail = result.Err.Error()
+	}
+
+	pageName := fmt.Sprintf("confluence:%s", result.DocMeta.Name)
+	switch result.Status {
+	case SyncCreated:
+		action.Diff = diff.Unified("/dev/null", pageName, "", step.content, 3)
+	case SyncUpdated:
+		// Diff against the local file as it will read back from Confluence
+		if !step.bodyChanged {
+			break
+		}
+		content, err := roundTrip(step.content, step.options)
+		if err != nil {
+			content = step.content
+		}
+		action.Diff = diff.Unified(pageName, pageName, step.remote, content, 3)
+	case SyncPulled:
+		action.Diff = diff.Unified(step.docPath, step.docPath, step.local, step.content, 3)
+	case SyncMerged:
+		action.Diff = diff.Unified(step.docPath, step.docPath, step.local, step.content, 3) +
+			diff.Unified(pageName, pageName, step.remote, step.content, 3)
+	}
+	return action
+}
+
+// note adds to the detail of the planned result
+func (step *syncStep) note(detail string) {
+	if step.result.Detail != "" {
+		step.result.Detail += "; "
+	}
+	step.result.Detail += detail
+}
+
+func (step *syncStep) noteUploads() {
+	if len(step.uploads) > 0 {
+		step.note("upload " + attachmentNames(step.uploads))
+	}
+}
+
+func (step *syncStep) fail(err error) *syncStep {
+	step.result.Status = SyncFailed
+	step.result.Err = err
+	return step
+}
+
+// apply carries out a planned step
+func (cmd *SyncConfluenceCommand) apply(step *syncStep) SyncResult {
+	result := step.result
+	logger.With(resultFields(result)).Debug("Applying %s for '%s' (%s)", result.Status, result.DocMeta.Name, step.docPath)
+	switch result.Status {
+	case SyncCreated:
+		created, err := cmd.Client.CreatePage(&CreateConfluencePage{
+			Title:        result.DocMeta.Name,
+			SpaceKey:     step.target.SpaceKey,
+			Body:         step.storageBody(step.content),
+			ParentPageID: step.parentPageID,
+		})
+		if err != nil {
+			result.Status = SyncFailed
+			result.Err = fmt.Errorf("failed to create page: %w", err)
+			return result
+		}
+		result.PageID = created.ID
+		err = uploadAttachments(cmd.Client, created.ID, step.uploads)
+		if err == nil {
+			err = cmd.applyLabels(step, created.ID)
+		}
+		if err != nil {
+			// Still record the page so that the next sync updates it
+			result = recordSync(cmd.SpecRepo, result, step.target, created, step.parentPageID, step.content)
+			result.Status = SyncFailed
+			result.Err = err
+			return result
+		}
+		step.target.Attachments = attachmentHashes(step.attachments)
+		return recordSync(cmd.SpecRepo, result, step.target, created, step.parentPageID, step.content)
+	case SyncUpdated, SyncMerged:
+		if result.Status == SyncMerged {
+			err := os.WriteFile(step.docPath, []byte(step.content), 0644)
+			if err != nil {
+				result.Status = SyncFailed
+				result.Err = fmt.Errorf("failed to write merged %s: %w", step.docPath, err)
+				return result
+			}
+		}
+		err := uploadAttachments(cmd.Client, step.page.ID, step.uploads)
+		if err == nil {
+			err = cmd.applyLabels(step, step.page.ID)
+		}
+		if err != nil {
+			result.Status = SyncFailed
+			result.Err = err
+			return result
+		}
+		step.target.Attachments = attachmentHashes(step.attachments)
+		if !step.bodyChanged && !step.move && !step.rename {
+			return recordSync(cmd.SpecRepo, result, step.target, step.page, step.currentParentPageID(), step.content)
+		}
+		return cmd.pushPage(result, step, step.storageBody(step.content))
+	case SyncMoved:
+		return cmd.pushPage(result, step, step.page.Body.Storage.Value)
+	case SyncPulled:
+		err := os.WriteFile(step.docPath, []byte(step.content), 0644)
+		if err != nil {
+			result.Status = SyncFailed
+			result.Err = fmt.Errorf("failed to pull page %s into %s: %w", step.page.ID, step.docPath, err)
+			return result
+		}
+		if step.move || step.rename {
+			return cmd.pushPage(result, step, step.page.Body.Storage.Value)
+		}
+		return recordSync(cmd.SpecRepo, result, step.target, step.page, step.currentParentPageID(), step.content)
+	case SyncUnchanged:
+		// The page already has all labels of the document
+		step.target.Labels = result.DocMeta.Labels
+		return recordSync(cmd.SpecRepo, result, step.target, step.page, step.currentParentPageID(), step.local)
+	}
+	return result
+}
+
+// pushPage overwrites the Confluence page with the given storage format body,
+// moving it under its parent page when needed
+func (cmd *SyncConfluenceCommand) pushPage(result SyncResult, step *syncStep, body string) SyncResult {
+	update := &UpdateConfluencePage{
+		PageID:  step.page.ID,
+		Title:   result.DocMeta.Name,
+		Body:    body,
+		Version: pageVersion(step.page) + 1,
+	}
+	if step.move {
+		update.ParentPageID = step.parentPageID
+	}
+	updated, err := cmd.Client.UpdatePage(update)
+	if err != nil {
+		result.Status = SyncFailed
+		result.Err = fmt.Errorf("failed to update page %s: %w", step.page.ID, err)
+		return result
+	}
+	return recordSync(cmd.SpecRepo, result, step.target, updated, step.currentParentPageID(), step.content)
+}
+
+// currentParentPageID is the parent the page has once the step is applied
+func (step *syncStep) currentParentPageID() string {
+	if step.move || step.page == nil {
+		return step.parentPageID
+	}
+	return ParentPageID(step.page)
+}
+
+// roundTrip normalises markdown by converting it to storage format and back
+func roundTrip(markdown string, options *linkOptions) (string, error) {
+	return converter.StorageToMarkdown(converter.MarkdownToStorage(markdown, options.storage), options.markdown)
+}
+
+func conflictDetail(target *spec.ConfluenceTarget, existing *goconfluence.Content) string {
+	if target.LastSyncedVersion == 0 {
+		return "page exists on Confluence but was never synced from this project"
+	}
+	return fmt.Sprintf("both the local file and the page changed since the last sync (version %d, now %d)",
+		target.LastSyncedVersion, pageVersion(existing))
+}
+
+// linkOptions resolve links between documents in both conversion directions
+type linkOptions struct {
+	storage  *converter.Options
+	markdown *converter.MarkdownOptions
+}
+
+func newLinkOptions(docMetaList []spec.DocMetaData) *linkOptions {
+	return &linkOptions{
+		storage:  storageOptions(docMetaList),
+		markdown: markdownOptions(docMetaList),
+	}
+}
+
+// storageOptions resolves relative links between documents to the titles of
+// their Confluence pages
+func storageOptions(docMetaList []spec.DocMetaData) *converter.Options {
+	return &converter.Options{
+		PageTitle: func(target string) (string, bool) {
+			for _, docMeta := range docMetaList {
+				if filepath.Base(target) == docMeta.FileName() {
+					return docMeta.Name, true
+				}
+			}
+			return "", false
+		},
+	}
+}
+
+// markdownOptions resolves links to Confluence pages back to the markdown
+// files of their documents
+func markdownOptions(docMetaList []spec.DocMetaData) *converter.MarkdownOptions {
+	return &converter.MarkdownOptions{
+		PageFile: func(title string) (string, bool) {
+			for _, docMeta := range docMetaList {
+				if docMeta.Name == title {
+					return docMeta.FileName(), true
+				}
+			}
+			return "", false
+		},
+	}
+}
+
+// printSyncSummary logs the result of every document and returns an error
+// when any of them conflicted or failed
+func printSyncSummary(operation string, results []SyncResult) error {
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Status]++
+		log := logger.With(resultFields(result))
+		switch result.Status {
+		case SyncMissing:
+			log.Warning("%-10s %s (%s)", result.Status, result.DocMeta.Name, result.Detail)
+		case SyncFailed:
+			log.Error("%-10s %s: %v", result.Status, result.DocMeta.Name, result.Err)
+		case SyncConflict:
+			log.Error("%-10s %s (page %s): %s", result.Status, result.DocMeta.Name, result.PageID, result.Detail)
+		case SyncOutdated:
+			log.Warning("%-10s %s (page %s): %s", result.Status, result.DocMeta.Name, result.PageID, result.Detail)
+		case SyncSkipped:
+			log.Info("%-10s %s (%s)", result.Status, result.DocMeta.Name, result.Detail)
+		default:
+			if result.Detail != "" {
+				log.Info("%-10s %s (page %s): %s", result.Status, result.DocMeta.Name, result.PageID, result.Detail)
+				continue
+			}
+			log.Info("%-10s %s (page %s)", result.Status, result.DocMeta.Name, result.PageID)
+		}
+	}
+
+	var parts []string
+	for _, status := range syncStatuses {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	summary := strings.Join(parts, ", ")
+	if counts[SyncConflict] > 0 {
+		logger.Info("Resolve conflicts with --force-local (keep the local file), --force-remote (keep the Confluence page) or --merge (three-way merge)")
+		return errs.Conflict("%s refused to overwrite %d conflicting document(s): %s", operation, counts[SyncConflict], summary)
+	}
+	if counts[SyncFailed] > 0 {
+		var failures []error
+		for _, result := range results {
+			if result.Status == SyncFailed {
+				failures = append(failures, result.Err)
+			}
+		}
+		return errs.Wrap(failureKind(failures), fmt.Errorf("%s finished with %d failure(s): %s", operation, counts[SyncFailed], summary))
+	}
+	logger.Success("%s finished: %s", operation, summary)
+	return nil
+}
+
+// failureKind is the kind all failures share, e.g. auth when every request
+// was rejected, and a general error when they differ
+func failureKind(failures []error) errs.Kind {
+	kind := errs.KindGeneral
+	for i, err := range failures {
+		if i == 0 {
+			kind = errs.KindOf(err)
+		} else if errs.KindOf(err) != kind {
+			return errs.KindGeneral
+		}
+	}
+	return kind
+}
+
+// resultFields are the structured log fields of a document's result
+func resultFields(result SyncResult) logger.Fields {
+	fields := logger.Fields{
+		"platform": "confluence",
+		"doc_id":   result.DocMeta.ID,
+		"status":   result.Status,
+	}
+	if result.PageID != "" {
+		fields["page_id"] = result.PageID
+	}
+	if result.Duration > 0 {
+		fields["duration_ms"] = result.Duration.Milliseconds()
+	}
+	return fields
+}
@@ -0,0 +1,433 @@
+package confluence
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+// newSyncProject creates a spec with a single document and its markdown file
+func newSyncProject(t *testing.T, markdown string) (*spec.SpecRepo, *spec.DocMetaData) {
+	t.Helper()
+	dir := t.TempDir()
+	specRepo := &spec.SpecRepo{
+		SpecFilePath:     filepath.Join(dir, "spec.md"),
+		SpecJsonFilePath: filepath.Join(dir, "spec.json"),
+	}
+	doc := spec.DocMetaData{ID: "arch", Name: "Architecture", Description: "How the parts fit together"}
+	err := specRepo.Save(&spec.DocSpec{Platforms: []string{"confluence"}, DocMeta: []spec.DocMetaData{doc}})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	writeDoc(t, specRepo, &doc, markdown)
+	return specRepo, &doc
+}
+
+func writeDoc(t *testing.T, specRepo *spec.SpecRepo, doc *spec.DocMetaData, markdown string) {
+	t.Helper()
+	err := os.WriteFile(specRepo.DocFilePath(doc), []byte(markdown), 0644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func syncTarget(t *testing.T, specRepo *spec.SpecRepo, id string) *spec.ConfluenceTarget {
+	t.Helper()
+	target, err := specRepo.GetConfluenceTarget(id)
+	if err != nil {
+		t.Fatalf("GetConfluenceTarget: %v", err)
+	}
+	if target == nil {
+		t.Fatalf("document %s has no Confluence target", id)
+	}
+	return target
+}
+
+func runSync(t *testing.T, specRepo *spec.SpecRepo, fake *fakeConfluence, resolution string) error {
+	t.Helper()
+	return NewSyncConfluenceCommand(specRepo, fake.client, "DOC", resolution, "").Run()
+}
+
+// contentWrites filters the requests that create or change a page
+func contentWrites(requests []string) []string {
+	var writes []string
+	for _, request := range requests {
+		if strings.HasPrefix(request, "POST /content/") && !strings.HasSuffix(request, "/label") ||
+			strings.HasPrefix(request, "PUT /content/") {
+			writes = append(writes, request)
+		}
+	}
+	return writes
+}
+
+func TestSyncCreateUpdateUnchanged(t *testing.T) {
+	fake := newFakeConfluence(t)
+	specRepo, doc := newSyncProject(t, "# Architecture\n\nThe first draft.\n")
+
+	// A document without a page is created and labelled as managed
+	err := runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	requests := fake.takeRequests()
+	if got := contentWrites(requests); !slices.Equal(got, []string{"POST /content/"}) {
+		t.Fatalf("first sync wrote %v, want a single page creation", got)
+	}
+	target := syncTarget(t, specRepo, doc.ID)
+	page := fake.page(target.PageID)
+	if page == nil {
+		t.Fatalf("spec.json maps page %s, which was not created", target.PageID)
+	}
+	if page.Title != "Architecture" || !strings.Contains(page.Body.Storage.Value, "The first draft.") {
+		t.Errorf("created page %q with body %q", page.Title, page.Body.Storage.Value)
+	}
+	if !slices.Contains(fake.labels[page.ID], ManagedLabel) {
+		t.Errorf("created page has labels %v, want %s", fake.labels[page.ID], ManagedLabel)
+	}
+	if target.LastSyncedVersion != 1 {
+		t.Errorf("LastSyncedVersion = %d after creation, want 1", target.LastSyncedVersion)
+	}
+	if want := spec.ContentHash("# Architecture\n\nThe first draft.\n"); target.LastSyncedHash != want {
+		t.Errorf("LastSyncedHash = %s, want %s", target.LastSyncedHash, want)
+	}
+
+	// Nothing changed, the page is only read
+	err = runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("unchanged sync: %v", err)
+	}
+	requests = fake.takeRequests()
+	if got := contentWrites(requests); len(got) > 0 {
+		t.Errorf("unchanged sync wrote %v", got)
+	}
+	if !slices.Contains(requests, "GET /content/"+page.ID) {
+		t.Errorf("unchanged sync did not look the page up by its mapped ID: %v", requests)
+	}
+	if got := syncTarget(t, specRepo, doc.ID); got.LastSyncedVersion != 1 || got.LastSyncedHash != target.LastSyncedHash {
+		t.Errorf("unchanged sync recorded version %d hash %s", got.LastSyncedVersion, got.LastSyncedHash)
+	}
+
+	// A local edit is pushed as the next version
+	writeDoc(t, specRepo, doc, "# Architecture\n\nThe second draft.\n")
+	err = runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("update sync: %v", err)
+	}
+	if got := contentWrites(fake.takeRequests()); !slices.Equal(got, []string{"PUT /content/" + page.ID}) {
+		t.Fatalf("update sync wrote %v, want a single page update", got)
+	}
+	page = fake.page(page.ID)
+	if page.Version.Number != 2 || !strings.Contains(page.Body.Storage.Value, "The second draft.") {
+		t.Errorf("updated page is version %d with body %q", page.Version.Number, page.Body.Storage.Value)
+	}
+	target = syncTarget(t, specRepo, doc.ID)
+	if target.LastSyncedVersion != 2 {
+		t.Errorf("LastSyncedVersion = %d after the update, want 2", target.LastSyncedVersion)
+	}
+	if want := spec.ContentHash("# Architecture\n\nThe second draft.\n"); target.LastSyncedHash != want {
+		t.Errorf("LastSyncedHash = %s after the update, want %s", target.LastSyncedHash, want)
+	}
+}
+
+func TestSyncRemoteVersionBump(t *testing.T) {
+	fake := newFakeConfluence(t)
+	specRepo, doc := newSyncProject(t, "# Architecture\n\nThe first draft.\n")
+	err := runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	target := syncTarget(t, specRepo, doc.ID)
+	fake.takeRequests()
+
+	// The page was edited on Confluence, the unchanged local file must not
+	// overwrite it
+	fake.editPage(target.PageID, "<p>Edited on Confluence.</p>")
+	err = runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("outdated sync: %v", err)
+	}
+	if got := contentWrites(fake.takeRequests()); len(got) > 0 {
+		t.Errorf("outdated sync wrote %v", got)
+	}
+	if got := syncTarget(t, specRepo, doc.ID); got.LastSyncedVersion != 1 {
+		t.Errorf("outdated sync recorded version %d, want 1", got.LastSyncedVersion)
+	}
+
+	// Both sides changed, sync refuses without a resolution
+	writeDoc(t, specRepo, doc, "# Architecture\n\nThe second draft.\n")
+	err = runSync(t, specRepo, fake, ResolveNone)
+	if err == nil {
+		t.Fatal("conflicting sync succeeded")
+	}
+	if got := contentWrites(fake.takeRequests()); len(got) > 0 {
+		t.Errorf("conflicting sync wrote %v", got)
+	}
+
+	// Keeping the local file updates on top of the remote version
+	err = runSync(t, specRepo, fake, ResolveLocal)
+	if err != nil {
+		t.Fatalf("forced sync: %v", err)
+	}
+	if got := contentWrites(fake.takeRequests()); !slices.Equal(got, []string{"PUT /content/" + target.PageID}) {
+		t.Fatalf("forced sync wrote %v, want a single page update", got)
+	}
+	page := fake.page(target.PageID)
+	if page.Version.Number != 3 || !strings.Contains(page.Body.Storage.Value, "The second draft.") {
+		t.Errorf("forced page is version %d with body %q", page.Version.Number, page.Body.Storage.Value)
+	}
+	target = syncTarget(t, specRepo, doc.ID)
+	if target.LastSyncedVersion != 3 {
+		t.Errorf("LastSyncedVersion = %d after the forced sync, want 3", target.LastSyncedVersion)
+	}
+	if want := spec.ContentHash("# Architecture\n\nThe second draft.\n"); target.LastSyncedHash != want {
+		t.Errorf("LastSyncedHash = %s after the forced sync, want %s", target.LastSyncedHash, want)
+	}
+}
+
+func TestPlanListsOrphanedPages(t *testing.T) {
+	fake := newFakeConfluence(t)
+	specRepo, _ := newSyncProject(t, "# Architecture\n\nThe first draft.\n")
+	err := runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	// A page docli created for a document that was deleted since, and a page
+	// created by hand
+	orphan := fake.addPage("Old Design", "<p>Replaced by the architecture.</p>", 4)
+	fake.labels[orphan.ID] = []string{ManagedLabel}
+	fake.addPage("Team Notes", "<p>Written by hand.</p>", 1)
+	fake.takeRequests()
+
+	syncPlan, err := NewSyncConfluenceCommand(specRepo, fake.client, "DOC", ResolveNone, "").Plan()
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if got := contentWrites(fake.takeRequests()); len(got) > 0 {
+		t.Errorf("Plan wrote %v", got)
+	}
+	var deletions []string
+	for _, action := range syncPlan.Actions {
+		if action.Action != "delete" {
+			continue
+		}
+		deletions = append(deletions, action.PageID)
+		if !strings.Contains(action.Diff, "+++ /dev/null") || !strings.Contains(action.Diff, "-Replaced by the architecture.") {
+			t.Errorf("delete of %s has diff:\n%s", action.Title, action.Diff)
+		}
+	}
+	if !slices.Equal(deletions, []string{orphan.ID}) {
+		t.Errorf("Plan deletes pages %v, want only the orphaned page %s", deletions, orphan.ID)
+	}
+	if !syncPlan.HasChanges() {
+		t.Error("a plan that deletes a page reports no changes")
+	}
+}
+
+// editRemote replaces text in the storage format body of a page, as an edit
+// on Confluence does
+func editRemote(t *testing.T, fake *fakeConfluence, id, old, new string) {
+	t.Helper()
+	body := fake.page(id).Body.Storage.Value
+	if !strings.Contains(body, old) {
+		t.Fatalf("page %s does not contain %q: %s", id, old, body)
+	}
+	fake.editPage(id, strings.Replace(body, old, new, 1))
+}
+
+func readDoc(t *testing.T, specRepo *spec.SpecRepo, doc *spec.DocMetaData) string {
+	t.Helper()
+	content, err := os.ReadFile(specRepo.DocFilePath(doc))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return string(content)
+}
+
+const sectionsDoc = "# Architecture\n\nThe intro.\n\n## Storage\n\nFiles on disk.\n\n## Network\n\nPlain HTTP.\n"
+
+func TestSyncResolveRemote(t *testing.T) {
+	fake := newFakeConfluence(t)
+	specRepo, doc := newSyncProject(t, sectionsDoc)
+	err := runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	target := syncTarget(t, specRepo, doc.ID)
+	fake.takeRequests()
+
+	// Both sides changed, keeping the page overwrites the local file
+	editRemote(t, fake, target.PageID, "Plain HTTP.", "HTTPS only.")
+	writeDoc(t, specRepo, doc, strings.Replace(sectionsDoc, "Files on disk.", "A database.", 1))
+	err = runSync(t, specRepo, fake, ResolveRemote)
+	if err != nil {
+		t.Fatalf("sync keeping the page: %v", err)
+	}
+	if got := contentWrites(fake.takeRequests()); len(got) > 0 {
+		t.Errorf("sync keeping the page wrote %v", got)
+	}
+	want := strings.Replace(sectionsDoc, "Plain HTTP.", "HTTPS only.", 1)
+	if got := readDoc(t, specRepo, doc); got != want {
+		t.Errorf("local file is\n%s\nwant\n%s", got, want)
+	}
+	target = syncTarget(t, specRepo, doc.ID)
+	if target.LastSyncedVersion != 2 || target.LastSyncedHash != spec.ContentHash(want) {
+		t.Errorf("recorded version %d hash %s after pulling version 2", target.LastSyncedVersion, target.LastSyncedHash)
+	}
+}
+
+func TestSyncResolveMerge(t *testing.T) {
+	fake := newFakeConfluence(t)
+	specRepo, doc := newSyncProject(t, sectionsDoc)
+	err := runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	target := syncTarget(t, specRepo, doc.ID)
+	fake.takeRequests()
+
+	// Edits of different sections merge cleanly, into the local file and the
+	// page
+	editRemote(t, fake, target.PageID, "Plain HTTP.", "HTTPS only.")
+	writeDoc(t, specRepo, doc, strings.Replace(sectionsDoc, "Files on disk.", "A database.", 1))
+	err = runSync(t, specRepo, fake, ResolveMerge)
+	if err != nil {
+		t.Fatalf("merging sync: %v", err)
+	}
+	if got := contentWrites(fake.takeRequests()); !slices.Equal(got, []string{"PUT /content/" + target.PageID}) {
+		t.Fatalf("merging sync wrote %v, want a single page update", got)
+	}
+	merged := strings.NewReplacer("Files on disk.", "A database.", "Plain HTTP.", "HTTPS only.").Replace(sectionsDoc)
+	if got := readDoc(t, specRepo, doc); got != merged {
+		t.Errorf("merged local file is\n%s\nwant\n%s", got, merged)
+	}
+	page := fake.page(target.PageID)
+	if page.Version.Number != 3 || !strings.Contains(page.Body.Storage.Value, "A database.") || !strings.Contains(page.Body.Storage.Value, "HTTPS only.") {
+		t.Errorf("merged page is version %d with body %q", page.Version.Number, page.Body.Storage.Value)
+	}
+	target = syncTarget(t, specRepo, doc.ID)
+	if target.LastSyncedVersion != 3 || target.LastSyncedHash != spec.ContentHash(merged) {
+		t.Errorf("recorded version %d hash %s after the merge", target.LastSyncedVersion, target.LastSyncedHash)
+	}
+
+	// Edits of the same section conflict and leave both sides alone
+	editRemote(t, fake, target.PageID, "HTTPS only.", "gRPC.")
+	local := strings.Replace(merged, "HTTPS only.", "WebSockets.", 1)
+	writeDoc(t, specRepo, doc, local)
+	err = runSync(t, specRepo, fake, ResolveMerge)
+	if err == nil {
+		t.Fatal("conflicting merge succeeded")
+	}
+	if got := contentWrites(fake.takeRequests()); len(got) > 0 {
+		t.Errorf("conflicting merge wrote %v", got)
+	}
+	if got := readDoc(t, specRepo, doc); got != local {
+		t.Errorf("conflicting merge changed the local file to\n%s", got)
+	}
+	if got := syncTarget(t, specRepo, doc.ID); got.LastSyncedVersion != 3 {
+		t.Errorf("conflicting merge recorded version %d, want 3", got.LastSyncedVersion)
+	}
+}
+
+// newFamilyProject creates a spec with a child document listed before its
+// parent and a third top-level document
+func newFamilyProject(t *testing.T) *spec.SpecRepo {
+	t.Helper()
+	specRepo, _ := newSyncProject(t, "# Architecture\n")
+	docs := []spec.DocMetaData{
+		{ID: "api", Name: "API", Description: "The endpoints", Parent: "guide"},
+		{ID: "guide", Name: "Guide", Description: "How to use it"},
+		{ID: "ops", Name: "Operations", Description: "How to run it"},
+	}
+	for i := range docs {
+		err := specRepo.AddDocMeta(&docs[i])
+		if err != nil {
+			t.Fatalf("AddDocMeta: %v", err)
+		}
+		writeDoc(t, specRepo, &docs[i], "# "+docs[i].Name+"\n")
+	}
+	return specRepo
+}
+
+func setParent(t *testing.T, specRepo *spec.SpecRepo, id, parent string) {
+	t.Helper()
+	err := specRepo.UpdateDocMeta(id, func(doc *spec.DocMetaData) {
+		doc.Parent = parent
+	})
+	if err != nil {
+		t.Fatalf("UpdateDocMeta: %v", err)
+	}
+}
+
+func TestSyncParents(t *testing.T) {
+	fake := newFakeConfluence(t)
+	specRepo := newFamilyProject(t)
+
+	// The parent is created first although the child comes first in the spec
+	err := runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	guide := syncTarget(t, specRepo, "guide")
+	api := syncTarget(t, specRepo, "api")
+	ops := syncTarget(t, specRepo, "ops")
+	if guide.ParentPageID != "" || ops.ParentPageID != "" {
+		t.Errorf("top-level documents were created under %q and %q", guide.ParentPageID, ops.ParentPageID)
+	}
+	if api.ParentPageID != guide.PageID || ParentPageID(fake.page(api.PageID)) != guide.PageID {
+		t.Errorf("child page is under %q, recorded %q, want the parent page %s", ParentPageID(fake.page(api.PageID)), api.ParentPageID, guide.PageID)
+	}
+	fake.takeRequests()
+
+	// A new parent moves the page, nothing else is written
+	setParent(t, specRepo, "api", "ops")
+	err = runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("re-parent sync: %v", err)
+	}
+	if got := contentWrites(fake.takeRequests()); !slices.Equal(got, []string{"PUT /content/" + api.PageID}) {
+		t.Fatalf("re-parent sync wrote %v, want a single page update", got)
+	}
+	if got := ParentPageID(fake.page(api.PageID)); got != ops.PageID {
+		t.Errorf("moved page is under %q, want %s", got, ops.PageID)
+	}
+	if got := syncTarget(t, specRepo, "api"); got.ParentPageID != ops.PageID || got.PageID != api.PageID {
+		t.Errorf("re-parent sync recorded page %s under %s", got.PageID, got.ParentPageID)
+	}
+}
+
+func TestSyncParentCycle(t *testing.T) {
+	fake := newFakeConfluence(t)
+	specRepo := newFamilyProject(t)
+	err := runSync(t, specRepo, fake, ResolveNone)
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	guide := syncTarget(t, specRepo, "guide")
+	ops := syncTarget(t, specRepo, "ops")
+	fake.takeRequests()
+
+	// The documents of a cycle fail without touching their pages, the child
+	// of a document in the cycle is still synced
+	setParent(t, specRepo, "guide", "ops")
+	setParent(t, specRepo, "ops", "guide")
+	writeDoc(t, specRepo, &spec.DocMetaData{ID: "guide", Name: "Guide"}, "# Guide\n\nChanged.\n")
+	writeDoc(t, specRepo, &spec.DocMetaData{ID: "api", Name: "API"}, "# API\n\nChanged.\n")
+	err = runSync(t, specRepo, fake, ResolveNone)
+	if err == nil || !strings.Contains(err.Error(), "2 failure(s)") {
+		t.Fatalf("sync with a cycle = %v, want the 2 documents of the cycle to fail", err)
+	}
+	api := syncTarget(t, specRepo, "api")
+	if got := contentWrites(fake.takeRequests()); !slices.Equal(got, []string{"PUT /content/" + api.PageID}) {
+		t.Errorf("sync with a cycle wrote %v, want only the child page", got)
+	}
+	if got := syncTarget(t, specRepo, "guide"); got.LastSyncedVersion != guide.LastSyncedVersion || got.ParentPageID != "" {
+		t.Errorf("page in the cycle was synced to version %d under %q", got.LastSyncedVersion, got.ParentPageID)
+	}
+	if got := syncTarget(t, specRepo, "ops"); got.LastSyncedVersion != ops.LastSyncedVersion {
+		t.Errorf("page in the cycle was synced to version %d", got.LastSyncedVersion)
+	}
+}
@@ -4,6 +4,8 @@ type CreateConfluencePage struct {
 	Title    string
 	SpaceKey string
 	Body     string
+	// ParentPageID is the page to create the page under, empty for the space root
+	ParentPageID string
 }
 
 type UpdateConfluencePage struct {
@@ -11,6 +13,8 @@ type UpdateConfluencePage struct {
 	Title   string
 	Body    string
 	Version int
+	// ParentPageID moves the page under another page, empty keeps its parent
+	ParentPageID string
 }
 
 type GetSpacePages struct {
@@ -18,3 +22,9 @@ type GetSpacePages struct {
 	Limit    int
 	Start    int
 }
+
+// PageAttachment is a file attached to a Confluence page
+type PageAttachment struct {
+	ID       string
+	Filename string
+}
@@ -0,0 +1,132 @@
+package converter
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenPairs name the testdata/<name>.md and <name>.xml files that convert
+// into each other. Conversions that lose information only run one way:
+// entity references are published as the characters they stand for, page
+// properties are kept in the document metadata rather than the markdown, and
+// raw HTML other than <details> is published as text.
+var goldenPairs = []struct {
+	name       string
+	toStorage  bool
+	toMarkdown bool
+}{
+	{name: "entities", toStorage: true},
+	{name: "storage_entities", toMarkdown: true},
+	{name: "tables", toStorage: true, toMarkdown: true},
+	{name: "paragraph_list", toStorage: true, toMarkdown: true},
+	{name: "code_blocks", toStorage: true, toMarkdown: true},
+	{name: "links", toStorage: true, toMarkdown: true},
+	{name: "details", toMarkdown: true},
+	{name: "headings", toStorage: true, toMarkdown: true},
+	{name: "blockquotes", toStorage: true, toMarkdown: true},
+	{name: "alerts", toStorage: true, toMarkdown: true},
+	{name: "expand", toStorage: true, toMarkdown: true},
+	{name: "raw_html", toStorage: true},
+}
+
+// goldenOptions link other_doc.md to the page "Other Doc"
+var goldenOptions = &Options{
+	PageTitle: func(target string) (string, bool) {
+		return "Other Doc", target == "other_doc.md"
+	},
+}
+
+var goldenMarkdownOptions = &MarkdownOptions{
+	PageFile: func(title string) (string, bool) {
+		return "other_doc.md", title == "Other Doc"
+	},
+}
+
+func TestGolden(t *testing.T) {
+	for _, pair := range goldenPairs {
+		t.Run(pair.name, func(t *testing.T) {
+			markdownPath := filepath.Join("testdata", pair.name+".md")
+			storagePath := filepath.Join("testdata", pair.name+".xml")
+
+			if pair.toStorage {
+				storage := MarkdownToStorage(readGolden(t, markdownPath), goldenOptions) + "\n"
+				compareGolden(t, storagePath, storage)
+			}
+			if pair.toMarkdown {
+				markdown, err := StorageToMarkdown(readGolden(t, storagePath), goldenMarkdownOptions)
+				if err != nil {
+					t.Fatalf("StorageToMarkdown: %v", err)
+				}
+				compareGolden(t, markdownPath, markdown)
+			}
+		})
+	}
+}
+
+// TestRoundTrip converts the markdown of every pair that runs both ways to
+// storage format and back, which must not change it
+func TestRoundTrip(t *testing.T) {
+	for _, pair := range goldenPairs {
+		if !pair.toStorage || !pair.toMarkdown {
+			continue
+		}
+		t.Run(pair.name, func(t *testing.T) {
+			markdown := readGolden(t, filepath.Join("testdata", pair.name+".md"))
+			roundTrip, err := StorageToMarkdown(MarkdownToStorage(markdown, goldenOptions), goldenMarkdownOptions)
+			if err != nil {
+				t.Fatalf("StorageToMarkdown: %v", err)
+			}
+			if roundTrip != markdown {
+				t.Errorf("round trip changed the markdown:\n--- got ---\n%s\n--- want ---\n%s", roundTrip, markdown)
+			}
+		})
+	}
+}
+
+func readGolden(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return string(content)
+}
+
+func compareGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		err := os.WriteFile(path, []byte(got), 0644)
+		if err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		return
+	}
+	want := readGolden(t, path)
+	if got != want {
+		t.Errorf("%s does not match:\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := map[string]string{
+		"&copy; 2024":         "© 2024",
+		"&#169; &#xA9;":       "© ©",
+		"a < b & c":           "a &lt; b &amp; c",
+		`\*not emphasis\*`:    "*not emphasis*",
+		"&unknown; entity":    "&amp;unknown; entity",
+		"&amp;lt;div&amp;gt;": "&amp;lt;div&amp;gt;",
+	}
+	for input, want := range tests {
+		if got := string(escapeText([]byte(input))); got != want {
+			t.Errorf("escapeText(%q) = %q, want %q", input, got, want)
+		}
+	}
+	if got := MarkdownToStorage("Fish &amp; chips", nil); !strings.Contains(got, "Fish &amp; chips") {
+		t.Errorf("MarkdownToStorage escaped an ampersand entity as %q", got)
+	}
+}
@@ -0,0 +1,636 @@
+package converter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// MarkdownOptions customise how Confluence storage format is converted back
+// to markdown
+type MarkdownOptions struct {
+	// PageFile resolves the title of a linked Confluence page to the relative
+	// markdown file it is synced from (e.g. "other_doc.md")
+	PageFile func(title string) (string, bool)
+	// AttachmentFile resolves the file name of a page attachment to the
+	// relative path of the local file it was uploaded from
+	AttachmentFile func(filename string) (string, bool)
+}
+
+// macroAlerts maps Confluence admonition macros back to GitHub alert markers
+var macroAlerts = map[string]string{
+	"info":    "NOTE",
+	"tip":     "TIP",
+	"note":    "IMPORTANT",
+	"warning": "WARNING",
+}
+
+// markdownLanguages maps Confluence code macro languages back to the names
+// commonly used in fenced code blocks
+var markdownLanguages = map[string]string{
+	"py":  "python",
+	"js":  "javascript",
+	"yml": "yaml",
+	"c#":  "csharp",
+}
+
+var whitespacePattern = regexp.MustCompile(`\s+`)
+var blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+var alignmentPattern = regexp.MustCompile(`(?i)text-align:\s*(left|center|right)\b`)
+
+// markupPattern finds the characters of plain text that markdown would read
+// as an HTML tag or an entity reference
+var markupPattern = regexp.MustCompile(`<[A-Za-z/!?]|&(#[0-9]+|#[xX][0-9A-Fa-f]+|[A-Za-z][A-Za-z0-9]*);`)
+
+// xmlNode is a minimal DOM of a storage format document
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	children []*xmlNode
+	text     string
+	isText   bool
+}
+
+// StorageToMarkdown converts Confluence storage format XHTML into
+// GitHub-flavoured markdown
+func StorageToMarkdown(storage string, options *MarkdownOptions) (string, error) {
+	if options == nil {
+		options = &MarkdownOptions{}
+	}
+	root, err := parseStorage(storage)
+	if err != nil {
+		return "", err
+	}
+
+	r := &markdownRenderer{options: options, anchors: map[string]string{}}
+	r.collectAnchors(root)
+	markdown := r.blocks(root.children)
+	markdown = blankLinesPattern.ReplaceAllString(strings.TrimSpace(markdown), "\n\n")
+	return markdown + "\n", nil
+}
+
+func parseStorage(storage string) (*xmlNode, error) {
+	decoder := xml.NewDecoder(strings.NewReader("<root>" + storage + "</root>"))
+	decoder.Strict = false
+	// xml.HTMLAutoClose would also match the local name of <ac:link>
+	decoder.AutoClose = []string{"br", "hr", "img", "col", "wbr"}
+	decoder.Entity = xml.HTMLEntity
+
+	root := &xmlNode{name: "root"}
+	stack := []*xmlNode{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse storage format: %w", err)
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: qualifiedName(t.Name), attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				node.attrs[qualifiedName(attr.Name)] = attr.Value
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &xmlNode{text: string(t), isText: true})
+		}
+	}
+	if len(root.children) == 1 && root.children[0].name == "root" {
+		return root.children[0], nil
+	}
+	return root, nil
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// child returns the first direct child element with the given name
+func (n *xmlNode) child(name string) *xmlNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// textContent returns the raw text of the node and all its descendants
+func (n *xmlNode) textContent() string {
+	if n.isText {
+		return n.text
+	}
+	var builder strings.Builder
+	for _, c := range n.children {
+		builder.WriteString(c.textContent())
+	}
+	return builder.String()
+}
+
+// macroParameter returns the value of a named structured macro parameter
+func (n *xmlNode) macroParameter(name string) string {
+	for _, c := range n.children {
+		if c.name == "ac:parameter" && c.attrs["ac:name"] == name {
+			return c.textContent()
+		}
+	}
+	return ""
+}
+
+type markdownRenderer struct {
+	options *MarkdownOptions
+	anchors map[string]string
+}
+
+// collectAnchors maps Confluence heading anchors back to GitHub heading slugs
+func (r *markdownRenderer) collectAnchors(node *xmlNode) {
+	for _, c := range node.children {
+		if isHeading(c.name) {
+			title := strings.TrimSpace(whitespacePattern.ReplaceAllString(c.textContent(), " "))
+			r.anchors[strings.ReplaceAll(title, " ", "")] = headingSlug(title)
+			continue
+		}
+		r.collectAnchors(c)
+	}
+}
+
+func isHeading(name string) bool {
+	return len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6'
+}
+
+func isBlock(node *xmlNode) bool {
+	if node.isText {
+		return false
+	}
+	switch node.name {
+	case "p", "ul", "ol", "table", "blockquote", "pre", "hr", "div",
+		"ac:task-list", "ac:structured-macro", "ac:layout", "ac:layout-section", "ac:layout-cell":
+		return true
+	}
+	return isHeading(node.name)
+}
+
+// blocks renders a sequence of nodes as markdown blocks separated by blank
+// lines; runs of inline nodes are grouped into paragraphs
+func (r *markdownRenderer) blocks(nodes []*xmlNode) string {
+	return r.joinBlocks(nodes, "\n\n")
+}
+
+// itemBlocks renders the content of a list item, keeping the list tight
+// unless the item is made of paragraphs
+func (r *markdownRenderer) itemBlocks(nodes []*xmlNode) string {
+	for _, node := range nodes {
+		if node.name == "p" {
+			return r.joinBlocks(nodes, "\n\n")
+		}
+	}
+	return r.joinBlocks(nodes, "\n")
+}
+
+func (r *markdownRenderer) joinBlocks(nodes []*xmlNode, separator string) string {
+	var builder strings.Builder
+	var inlineRun []*xmlNode
+
+	write := func(text string) {
+		if builder.Len() > 0 {
+			builder.WriteString(separator)
+		}
+		builder.WriteString(text)
+	}
+	flush := func() {
+		if text := strings.TrimSpace(r.inlines(inlineRun)); text != "" {
+			write(text)
+		}
+		inlineRun = nil
+	}
+
+	for _, node := range nodes {
+		if !isBlock(node) {
+			inlineRun = append(inlineRun, node)
+			continue
+		}
+		flush()
+		if block := r.block(node); strings.TrimSpace(block) != "" {
+			write(strings.TrimRight(block, "\n"))
+		}
+	}
+	flush()
+	return builder.String()
+}
+
+func (r *markdownRenderer) block(node *xmlNode) string {
+	switch {
+	case isHeading(node.name):
+		level := int(node.name[1] - '0')
+		return strings.Repeat("#", level) + " " + softBreaksToSpaces(strings.TrimSpace(r.inlines(node.children)))
+	}
+
+	switch node.name {
+	case "p":
+		return strings.TrimSpace(r.inlines(node.children))
+	case "hr":
+		return "---"
+	case "ul", "ol":
+		return r.list(node)
+	case "table":
+		return r.table(node)
+	case "blockquote":
+		return prefixLines(r.blocks(node.children), "> ")
+	case "pre":
+		return fence("", node.textContent())
+	case "ac:task-list":
+		return r.taskList(node)
+	case "ac:structured-macro":
+		return r.macro(node)
+	}
+	return r.blocks(node.children)
+}
+
+func (r *markdownRenderer) macro(node *xmlNode) string {
+	name := node.attrs["ac:name"]
+	switch name {
+	case "code", "noformat":
+		language := strings.ToLower(node.macroParameter("language"))
+		if mapped, ok := markdownLanguages[language]; ok {
+			language = mapped
+		}
+		body := ""
+		if plain := node.child("ac:plain-text-body"); plain != nil {
+			body = plain.textContent()
+		}
+		return fence(language, body)
+	case "details":
+		// Page properties are kept in the document metadata, not the markdown
+		return ""
+	case "expand":
+		summary := ""
+		if title := node.macroParameter("title"); title != "" {
+			summary = "\n<summary>" + escapeMarkup(title) + "</summary>"
+		}
+		content := ""
+		if body := node.child("ac:rich-text-body"); body != nil {
+			content = r.blocks(body.children) + "\n\n"
+		}
+		return "<details>" + summary + "\n\n" + content + "</details>"
+	}
+
+	body := node.child("ac:rich-text-body")
+	if body == nil {
+		return ""
+	}
+	content := r.blocks(body.children)
+	if alert, ok := macroAlerts[name]; ok {
+		return prefixLines("[!"+alert+"]\n"+content, "> ")
+	}
+	return content
+}
+
+func (r *markdownRenderer) list(node *xmlNode) string {
+	var builder strings.Builder
+	number := 1
+	if start := node.attrs["start"]; start != "" {
+		fmt.Sscanf(start, "%d", &number)
+	}
+
+	for _, item := range node.children {
+		if item.name != "li" {
+			continue
+		}
+		marker := "- "
+		if node.name == "ol" {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+		content := r.itemBlocks(item.children)
+		builder.WriteString(marker)
+		builder.WriteString(indentContinuation(content, strings.Repeat(" ", len(marker))))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (r *markdownRenderer) taskList(node *xmlNode) string {
+	var builder strings.Builder
+	for _, task := range node.children {
+		if task.name != "ac:task" {
+			continue
+		}
+		checkBox := "[ ]"
+		if status := task.child("ac:task-status"); status != nil && strings.TrimSpace(status.textContent()) == "complete" {
+			checkBox = "[x]"
+		}
+		content := ""
+		if body := task.child("ac:task-body"); body != nil {
+			content = r.itemBlocks(body.children)
+		}
+		builder.WriteString("- " + checkBox + " ")
+		builder.WriteString(indentContinuation(content, "  "))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (r *markdownRenderer) table(node *xmlNode) string {
+	var rows [][]string
+	var alignments []string
+	headerRow := false
+	var collectRows func(n *xmlNode)
+	collectRows = func(n *xmlNode) {
+		for _, c := range n.children {
+			switch c.name {
+			case "tbody", "thead", "tfoot":
+				collectRows(c)
+			case "tr":
+				var cells []string
+				for _, cell := range c.children {
+					if cell.name != "td" && cell.name != "th" {
+						continue
+					}
+					if cell.name == "th" && len(rows) == 0 {
+						headerRow = true
+					}
+					// A column is aligned like the first of its cells that has
+					// an alignment
+					if column := len(cells); column >= len(alignments) {
+						alignments = append(alignments, cellAlignment(cell))
+					} else if alignments[column] == "" {
+						alignments[column] = cellAlignment(cell)
+					}
+					text := strings.TrimSpace(r.inlines(flattenBlocks(cell.children)))
+					text = strings.ReplaceAll(text, "|", `\|`)
+					text = softBreaksToSpaces(strings.ReplaceAll(text, hardBreak, "<br>"))
+					cells = append(cells, text)
+				}
+				rows = append(rows, cells)
+			}
+		}
+	}
+	collectRows(node)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	for len(alignments) < columns {
+		alignments = append(alignments, "")
+	}
+	if !headerRow {
+		rows = append([][]string{make([]string, columns)}, rows...)
+	}
+
+	var builder strings.Builder
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		builder.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			builder.WriteString("|")
+			for column := range columns {
+				builder.WriteString(" " + alignmentDelimiters[alignments[column]] + " |")
+			}
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
+
+// alignmentDelimiters maps the text-align of a column to the delimiter row
+// of a markdown table
+var alignmentDelimiters = map[string]string{
+	"":       "---",
+	"left":   ":---",
+	"center": ":---:",
+	"right":  "---:",
+}
+
+// cellAlignment reads the text-align style of a table cell, empty when it has
+// none or an alignment markdown cannot express
+func cellAlignment(cell *xmlNode) string {
+	match := alignmentPattern.FindStringSubmatch(cell.attrs["style"])
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(match[1])
+}
+
+// flattenBlocks turns paragraphs inside table cells into inline content
+// separated by line breaks
+func flattenBlocks(nodes []*xmlNode) []*xmlNode {
+	var flattened []*xmlNode
+	for _, node := range nodes {
+		if node.name == "p" {
+			if len(flattened) > 0 {
+				flattened = append(flattened, &xmlNode{name: "br"})
+			}
+			flattened = append(flattened, node.children...)
+			continue
+		}
+		flattened = append(flattened, node)
+	}
+	return flattened
+}
+
+func (r *markdownRenderer) inlines(nodes []*xmlNode) string {
+	var builder strings.Builder
+	for _, node := range nodes {
+		builder.WriteString(r.inline(node))
+	}
+	return builder.String()
+}
+
+func (r *markdownRenderer) inline(node *xmlNode) string {
+	if node.isText {
+		// Line breaks are kept as soft line breaks, other whitespace collapses
+		text := whitespacePattern.ReplaceAllStringFunc(node.text, func(space string) string {
+			if strings.Contains(space, "\n") {
+				return "\n"
+			}
+			return " "
+		})
+		return escapeMarkup(text)
+	}
+
+	switch node.name {
+	case "strong", "b":
+		return wrapInline(r.inlines(node.children), "**")
+	case "em", "i":
+		return wrapInline(r.inlines(node.children), "*")
+	case "del", "s":
+		return wrapInline(r.inlines(node.children), "~~")
+	case "code":
+		return "`" + node.textContent() + "`"
+	case "br":
+		return hardBreak
+	case "a":
+		text := strings.TrimSpace(r.inlines(node.children))
+		href := node.attrs["href"]
+		if text == "" || text == href {
+			return "<" + href + ">"
+		}
+		return "[" + text + "](" + href + ")"
+	case "ac:link":
+		return r.link(node)
+	case "ac:image":
+		return r.image(node)
+	case "ac:structured-macro", "ac:parameter", "ac:task-status":
+		return ""
+	}
+	return r.inlines(node.children)
+}
+
+func (r *markdownRenderer) link(node *xmlNode) string {
+	text := ""
+	if body := node.child("ac:plain-text-link-body"); body != nil {
+		text = body.textContent()
+	} else if body := node.child("ac:link-body"); body != nil {
+		text = strings.TrimSpace(r.inlines(body.children))
+	}
+
+	destination := ""
+	if attachment := node.child("ri:attachment"); attachment != nil {
+		filename := attachment.attrs["ri:filename"]
+		destination = r.attachmentPath(filename)
+		if text == "" {
+			text = filename
+		}
+	}
+	if page := node.child("ri:page"); page != nil {
+		title := page.attrs["ri:content-title"]
+		if file, ok := r.lookupPageFile(title); ok {
+			destination = file
+		} else {
+			destination = title
+		}
+		if text == "" {
+			text = title
+		}
+	}
+	if anchor := node.attrs["ac:anchor"]; anchor != "" {
+		if slug, ok := r.anchors[anchor]; ok && destination == "" {
+			anchor = slug
+		}
+		destination += "#" + anchor
+		if text == "" {
+			text = node.attrs["ac:anchor"]
+		}
+	}
+	if destination == "" {
+		return text
+	}
+	return "[" + text + "](" + destination + ")"
+}
+
+func (r *markdownRenderer) lookupPageFile(title string) (string, bool) {
+	if r.options.PageFile == nil {
+		return "", false
+	}
+	return r.options.PageFile(title)
+}
+
+func (r *markdownRenderer) attachmentPath(filename string) string {
+	if r.options.AttachmentFile != nil {
+		if path, ok := r.options.AttachmentFile(filename); ok {
+			return path
+		}
+	}
+	return filename
+}
+
+func (r *markdownRenderer) image(node *xmlNode) string {
+	source := ""
+	if attachment := node.child("ri:attachment"); attachment != nil {
+		source = r.attachmentPath(attachment.attrs["ri:filename"])
+	} else if url := node.child("ri:url"); url != nil {
+		source = url.attrs["ri:value"]
+	}
+	if source == "" {
+		return ""
+	}
+	image := "![" + node.attrs["ac:alt"] + "](" + source
+	if title := node.attrs["ac:title"]; title != "" {
+		image += ` "` + title + `"`
+	}
+	return image + ")"
+}
+
+// hardBreak is a markdown line break, a line ending in two spaces
+const hardBreak = "  \n"
+
+// softBreaksToSpaces joins the lines of text that cannot span lines in
+// markdown, e.g. headings and table cells
+func softBreaksToSpaces(text string) string {
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// wrapInline surrounds text with a markdown emphasis marker, keeping
+// surrounding whitespace outside of the marker
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	leading := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	trailing := text[len(strings.TrimRight(text, " ")):]
+	return leading + marker + trimmed + marker + trailing
+}
+
+// escapeMarkup keeps text such as "&lt;div&gt;" on a page from turning into
+// HTML or an entity in markdown
+func escapeMarkup(text string) string {
+	return markupPattern.ReplaceAllStringFunc(text, func(markup string) string {
+		if markup[0] == '<' {
+			return "&lt;" + markup[1:]
+		}
+		return "&amp;" + markup[1:]
+	})
+}
+
+func fence(language, code string) string {
+	marker := "```"
+	for strings.Contains(code, marker) {
+		marker += "`"
+	}
+	return marker + language + "\n" + strings.TrimSuffix(code, "\n") + "\n" + marker
+}
+
+func prefixLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indentContinuation indents every line but the first, so that nested
+// content stays inside its list item
+func indentContinuation(text, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,581 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Options customise how markdown is converted to Confluence storage format
+type Options struct {
+	// PageTitle resolves a relative link target (e.g. "other_doc.md") to the
+	// title of the Confluence page it is published as
+	PageTitle func(target string) (string, bool)
+	// Attachment resolves a relative image or file reference (e.g.
+	// "img/arch.png") to the file name of the page attachment it is uploaded as
+	Attachment func(target string) (string, bool)
+}
+
+// alertMacros maps GitHub alert markers to Confluence admonition macros
+var alertMacros = map[string]string{
+	"NOTE":      "info",
+	"TIP":       "tip",
+	"IMPORTANT": "note",
+	"WARNING":   "warning",
+	"CAUTION":   "warning",
+}
+
+// codeLanguages maps common fenced code languages to the names understood by
+// the Confluence code macro
+var codeLanguages = map[string]string{
+	"sh":         "bash",
+	"shell":      "bash",
+	"zsh":        "bash",
+	"console":    "bash",
+	"python":     "py",
+	"javascript": "js",
+	"typescript": "js",
+	"ts":         "js",
+	"yaml":       "yml",
+	"html":       "xml",
+	"csharp":     "c#",
+	"cs":         "c#",
+	"c++":        "cpp",
+	"golang":     "go",
+}
+
+var alertPattern = regexp.MustCompile(`^\s*\[!([A-Za-z]+)\]\s*$`)
+var voidElementPattern = regexp.MustCompile(`(?i)<(br|hr|img)(\s[^<>]*?)?\s*/?>`)
+
+// detailsOpenPattern matches the start of a <details> block with its optional
+// summary, detailsClosePattern its end
+var detailsOpenPattern = regexp.MustCompile(`(?is)^<details(?:\s[^>]*)?>\s*(?:<summary(?:\s[^>]*)?>(.*?)</summary>)?(.*)$`)
+var detailsClosePattern = regexp.MustCompile(`(?is)^(.*?)</details>$`)
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+var markdownParser = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// MarkdownToStorage converts GitHub-flavoured markdown into Confluence storage
+// format XHTML
+func MarkdownToStorage(markdown string, options *Options) string {
+	if options == nil {
+		options = &Options{}
+	}
+	source := []byte(markdown)
+	document := markdownParser.Parser().Parse(text.NewReader(source))
+
+	r := &storageRenderer{
+		source:  source,
+		options: options,
+		anchors: map[string]string{},
+	}
+	r.collectAnchors(document)
+	r.renderChildren(document)
+	return strings.TrimSpace(r.buf.String())
+}
+
+type storageRenderer struct {
+	buf     bytes.Buffer
+	source  []byte
+	options *Options
+	anchors map[string]string
+	// openDetails counts the expand macros opened by <details> blocks,
+	// detailsBase how many of them were open when the current container
+	// started, which must be closed before it ends
+	openDetails int
+	detailsBase int
+}
+
+// collectAnchors maps GitHub heading slugs to the anchor names Confluence
+// generates for the same headings
+func (r *storageRenderer) collectAnchors(document ast.Node) {
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if heading, ok := node.(*ast.Heading); ok && entering {
+			title := r.plainText(heading)
+			r.anchors[headingSlug(title)] = strings.ReplaceAll(title, " ", "")
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+func (r *storageRenderer) renderChildren(node ast.Node) {
+	base := r.detailsBase
+	r.detailsBase = r.openDetails
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		r.render(child)
+	}
+	// A <details> block without its closing tag ends with its container
+	for r.openDetails > r.detailsBase {
+		r.closeDetails()
+	}
+	r.detailsBase = base
+}
+
+func (r *storageRenderer) render(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.Paragraph:
+		r.buf.WriteString("<p>")
+		r.renderChildren(n)
+		r.buf.WriteString("</p>\n")
+	case *ast.TextBlock:
+		r.renderChildren(n)
+	case *ast.Heading:
+		fmt.Fprintf(&r.buf, "<h%d>", n.Level)
+		r.renderChildren(n)
+		fmt.Fprintf(&r.buf, "</h%d>\n", n.Level)
+	case *ast.ThematicBreak:
+		r.buf.WriteString("<hr />\n")
+	case *ast.FencedCodeBlock:
+		r.renderCodeMacro(string(n.Language(r.source)), n.Lines())
+	case *ast.CodeBlock:
+		r.renderCodeMacro("", n.Lines())
+	case *ast.Blockquote:
+		r.renderBlockquote(n)
+	case *ast.List:
+		r.renderList(n)
+	case *ast.ListItem:
+		r.buf.WriteString("<li>")
+		r.renderChildren(n)
+		r.buf.WriteString("</li>\n")
+	case *ast.HTMLBlock:
+		r.renderHTMLBlock(n)
+	case *extast.Table:
+		r.renderTable(n)
+	case *ast.Text:
+		r.renderText(n)
+	case *ast.String:
+		r.buf.WriteString(html.EscapeString(string(n.Value)))
+	case *ast.CodeSpan:
+		r.buf.WriteString("<code>")
+		r.buf.WriteString(html.EscapeString(r.plainText(n)))
+		r.buf.WriteString("</code>")
+	case *ast.Emphasis:
+		tag := "em"
+		if n.Level >= 2 {
+			tag = "strong"
+		}
+		r.buf.WriteString("<" + tag + ">")
+		r.renderChildren(n)
+		r.buf.WriteString("</" + tag + ">")
+	case *extast.Strikethrough:
+		r.buf.WriteString("<del>")
+		r.renderChildren(n)
+		r.buf.WriteString("</del>")
+	case *ast.Link:
+		r.renderLink(string(n.Destination), n)
+	case *ast.AutoLink:
+		destination := string(n.URL(r.source))
+		fmt.Fprintf(&r.buf, `<a href="%s">%s</a>`, html.EscapeString(destination), html.EscapeString(string(n.Label(r.source))))
+	case *ast.Image:
+		r.renderImage(n)
+	case *ast.RawHTML:
+		var raw strings.Builder
+		for i := 0; i < n.Segments.Len(); i++ {
+			segment := n.Segments.At(i)
+			raw.Write(segment.Value(r.source))
+		}
+		r.buf.WriteString(closeVoidElements(raw.String()))
+	case *extast.TaskCheckBox:
+		// Handled by renderTaskList
+	default:
+		r.renderChildren(n)
+	}
+}
+
+func (r *storageRenderer) renderText(n *ast.Text) {
+	r.buf.Write(escapeText(n.Value(r.source)))
+	if n.HardLineBreak() {
+		r.buf.WriteString("<br />")
+	} else if n.SoftLineBreak() {
+		r.buf.WriteString("\n")
+	}
+}
+
+func (r *storageRenderer) renderCodeMacro(language string, lines *text.Segments) {
+	var code strings.Builder
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		code.Write(segment.Value(r.source))
+	}
+
+	r.buf.WriteString(`<ac:structured-macro ac:name="code">`)
+	if language != "" {
+		language = strings.ToLower(language)
+		if mapped, ok := codeLanguages[language]; ok {
+			language = mapped
+		}
+		fmt.Fprintf(&r.buf, `<ac:parameter ac:name="language">%s</ac:parameter>`, html.EscapeString(language))
+	}
+	r.buf.WriteString("<ac:plain-text-body>")
+	r.buf.WriteString(cdata(strings.TrimSuffix(code.String(), "\n")))
+	r.buf.WriteString("</ac:plain-text-body></ac:structured-macro>\n")
+}
+
+// renderBlockquote renders GitHub alerts ("> [!NOTE]") as admonition macros
+// and everything else as a plain blockquote
+func (r *storageRenderer) renderBlockquote(n *ast.Blockquote) {
+	macro := ""
+	if paragraph, ok := n.FirstChild().(*ast.Paragraph); ok && paragraph.Lines().Len() > 0 {
+		firstLine := paragraph.Lines().At(0)
+		if match := alertPattern.FindSubmatch(firstLine.Value(r.source)); match != nil {
+			macro = alertMacros[strings.ToUpper(string(match[1]))]
+			if macro != "" {
+				r.skipFirstLine(paragraph, firstLine.Stop)
+			}
+		}
+	}
+
+	if macro == "" {
+		r.buf.WriteString("<blockquote>\n")
+		r.renderChildren(n)
+		r.buf.WriteString("</blockquote>\n")
+		return
+	}
+
+	if first := n.FirstChild(); first.ChildCount() == 0 {
+		n.RemoveChild(n, first)
+	}
+	fmt.Fprintf(&r.buf, `<ac:structured-macro ac:name="%s"><ac:rich-text-body>`+"\n", macro)
+	r.renderChildren(n)
+	r.buf.WriteString("</ac:rich-text-body></ac:structured-macro>\n")
+}
+
+// renderHTMLBlock renders <details> blocks as the expand macro, the markdown
+// between <details> and </details> becomes its body. Confluence rejects other
+// raw HTML, it is published as text, and comments are left out.
+func (r *storageRenderer) renderHTMLBlock(n *ast.HTMLBlock) {
+	if n.HTMLBlockType == ast.HTMLBlockType2 {
+		return
+	}
+	var builder strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		builder.Write(segment.Value(r.source))
+	}
+	if n.HasClosure() {
+		builder.Write(n.ClosureLine.Value(r.source))
+	}
+	raw := strings.TrimSpace(builder.String())
+
+	if match := detailsOpenPattern.FindStringSubmatch(raw); match != nil {
+		r.openDetails++
+		r.buf.WriteString(`<ac:structured-macro ac:name="expand">`)
+		if title := strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(match[1], ""))); title != "" {
+			fmt.Fprintf(&r.buf, `<ac:parameter ac:name="title">%s</ac:parameter>`, html.EscapeString(title))
+		}
+		r.buf.WriteString("<ac:rich-text-body>\n")
+		raw = strings.TrimSpace(match[2])
+	}
+	closing := false
+	if r.openDetails > r.detailsBase {
+		if match := detailsClosePattern.FindStringSubmatch(raw); match != nil {
+			raw = strings.TrimSpace(match[1])
+			closing = true
+		}
+	}
+	if raw != "" {
+		r.buf.WriteString("<p>" + html.EscapeString(raw) + "</p>\n")
+	}
+	if closing {
+		r.closeDetails()
+	}
+}
+
+func (r *storageRenderer) closeDetails() {
+	r.openDetails--
+	r.buf.WriteString("</ac:rich-text-body></ac:structured-macro>\n")
+}
+
+// skipFirstLine removes the inline nodes of the alert marker line from the
+// paragraph, leaving it empty when the marker stands alone
+func (r *storageRenderer) skipFirstLine(paragraph *ast.Paragraph, lineEnd int) {
+	for child := paragraph.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		textNode, ok := child.(*ast.Text)
+		if !ok || textNode.Segment.Start >= lineEnd {
+			break
+		}
+		paragraph.RemoveChild(paragraph, child)
+		child = next
+	}
+}
+
+func (r *storageRenderer) renderList(n *ast.List) {
+	if isTaskList(n) {
+		r.renderTaskList(n)
+		return
+	}
+
+	if n.IsOrdered() {
+		if n.Start > 1 {
+			fmt.Fprintf(&r.buf, "<ol start=\"%d\">\n", n.Start)
+		} else {
+			r.buf.WriteString("<ol>\n")
+		}
+		r.renderChildren(n)
+		r.buf.WriteString("</ol>\n")
+		return
+	}
+	r.buf.WriteString("<ul>\n")
+	r.renderChildren(n)
+	r.buf.WriteString("</ul>\n")
+}
+
+func (r *storageRenderer) renderTaskList(n *ast.List) {
+	r.buf.WriteString("<ac:task-list>\n")
+	for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+		status := "incomplete"
+		if checkBox := taskCheckBox(item); checkBox != nil && checkBox.IsChecked {
+			status = "complete"
+		}
+		fmt.Fprintf(&r.buf, "<ac:task><ac:task-status>%s</ac:task-status><ac:task-body>", status)
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			if child == item.FirstChild() {
+				r.renderChildren(child)
+				continue
+			}
+			r.render(child)
+		}
+		r.buf.WriteString("</ac:task-body></ac:task>\n")
+	}
+	r.buf.WriteString("</ac:task-list>\n")
+}
+
+func isTaskList(n *ast.List) bool {
+	if n.ChildCount() == 0 {
+		return false
+	}
+	for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+		if taskCheckBox(item) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func taskCheckBox(item ast.Node) *extast.TaskCheckBox {
+	if item.FirstChild() == nil {
+		return nil
+	}
+	checkBox, _ := item.FirstChild().FirstChild().(*extast.TaskCheckBox)
+	return checkBox
+}
+
+func (r *storageRenderer) renderTable(n *extast.Table) {
+	r.buf.WriteString("<table><tbody>\n")
+	for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+		cellTag := "td"
+		if _, isHeader := row.(*extast.TableHeader); isHeader {
+			cellTag = "th"
+		}
+		r.buf.WriteString("<tr>")
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			tableCell := cell.(*extast.TableCell)
+			if tableCell.Alignment == extast.AlignNone {
+				fmt.Fprintf(&r.buf, "<%s>", cellTag)
+			} else {
+				fmt.Fprintf(&r.buf, `<%s style="text-align: %s;">`, cellTag, tableCell.Alignment)
+			}
+			r.renderChildren(cell)
+			fmt.Fprintf(&r.buf, "</%s>", cellTag)
+		}
+		r.buf.WriteString("</tr>\n")
+	}
+	r.buf.WriteString("</tbody></table>\n")
+}
+
+// renderLink turns in-page anchors and links to other synced documents into
+// Confluence links and leaves everything else as a regular hyperlink
+func (r *storageRenderer) renderLink(destination string, n ast.Node) {
+	if isExternal(destination) {
+		fmt.Fprintf(&r.buf, `<a href="%s">`, html.EscapeString(destination))
+		r.renderChildren(n)
+		r.buf.WriteString("</a>")
+		return
+	}
+
+	target, fragment, _ := strings.Cut(destination, "#")
+	anchor := fragment
+	if confluenceAnchor, ok := r.anchors[fragment]; ok && target == "" {
+		anchor = confluenceAnchor
+	}
+
+	pageTitle := ""
+	if target != "" {
+		title, ok := "", false
+		if r.options.PageTitle != nil {
+			title, ok = r.options.PageTitle(target)
+		}
+		if !ok {
+			if filename, ok := r.lookupAttachment(target); ok {
+				fmt.Fprintf(&r.buf, `<ac:link><ri:attachment ri:filename="%s" />`, html.EscapeString(filename))
+				r.buf.WriteString("<ac:plain-text-link-body>")
+				r.buf.WriteString(cdata(r.plainText(n)))
+				r.buf.WriteString("</ac:plain-text-link-body></ac:link>")
+				return
+			}
+			fmt.Fprintf(&r.buf, `<a href="%s">`, html.EscapeString(destination))
+			r.renderChildren(n)
+			r.buf.WriteString("</a>")
+			return
+		}
+		pageTitle = title
+	}
+
+	r.buf.WriteString("<ac:link")
+	if anchor != "" {
+		fmt.Fprintf(&r.buf, ` ac:anchor="%s"`, html.EscapeString(anchor))
+	}
+	r.buf.WriteString(">")
+	if pageTitle != "" {
+		fmt.Fprintf(&r.buf, `<ri:page ri:content-title="%s" />`, html.EscapeString(pageTitle))
+	}
+	r.buf.WriteString("<ac:plain-text-link-body>")
+	r.buf.WriteString(cdata(r.plainText(n)))
+	r.buf.WriteString("</ac:plain-text-link-body></ac:link>")
+}
+
+func (r *storageRenderer) renderImage(n *ast.Image) {
+	destination := string(n.Destination)
+	alt := r.plainText(n)
+
+	r.buf.WriteString("<ac:image")
+	if alt != "" {
+		fmt.Fprintf(&r.buf, ` ac:alt="%s"`, html.EscapeString(alt))
+	}
+	if len(n.Title) > 0 {
+		fmt.Fprintf(&r.buf, ` ac:title="%s"`, html.EscapeString(string(n.Title)))
+	}
+	if !isExternal(destination) {
+		if filename, ok := r.lookupAttachment(destination); ok {
+			fmt.Fprintf(&r.buf, `><ri:attachment ri:filename="%s" /></ac:image>`, html.EscapeString(filename))
+			return
+		}
+	}
+	fmt.Fprintf(&r.buf, `><ri:url ri:value="%s" /></ac:image>`, html.EscapeString(destination))
+}
+
+// PropertiesToStorage renders page properties as a Confluence page properties
+// (details) macro, so that they show up in page property reports. Keys are
+// rendered in the given order.
+func PropertiesToStorage(keys []string, properties map[string]string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(`<ac:structured-macro ac:name="details" ac:schema-version="1"><ac:rich-text-body><table><tbody>`)
+	for _, key := range keys {
+		fmt.Fprintf(&builder, "<tr><th>%s</th><td>%s</td></tr>", html.EscapeString(key), html.EscapeString(properties[key]))
+	}
+	builder.WriteString("</tbody></table></ac:rich-text-body></ac:structured-macro>")
+	return builder.String()
+}
+
+func (r *storageRenderer) lookupAttachment(target string) (string, bool) {
+	if r.options.Attachment == nil {
+		return "", false
+	}
+	return r.options.Attachment(target)
+}
+
+// LocalReferences lists the relative targets of the images and links in
+// markdown, without fragments and in order of appearance. In-page anchors and
+// external URLs are left out.
+func LocalReferences(markdown string) []string {
+	source := []byte(markdown)
+	document := markdownParser.Parser().Parse(text.NewReader(source))
+
+	var references []string
+	seen := map[string]bool{}
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		destination := ""
+		switch n := node.(type) {
+		case *ast.Image:
+			destination = string(n.Destination)
+		case *ast.Link:
+			destination = string(n.Destination)
+		default:
+			return ast.WalkContinue, nil
+		}
+		target, _, _ := strings.Cut(destination, "#")
+		if target != "" && !isExternal(target) && !seen[target] {
+			seen[target] = true
+			references = append(references, target)
+		}
+		return ast.WalkContinue, nil
+	})
+	return references
+}
+
+// plainText returns the unformatted text content of an inline node tree
+func (r *storageRenderer) plainText(node ast.Node) string {
+	var builder strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch c := child.(type) {
+		case *ast.Text:
+			builder.Write(c.Value(r.source))
+			if c.SoftLineBreak() {
+				builder.WriteString(" ")
+			}
+		case *ast.String:
+			builder.Write(c.Value)
+		default:
+			builder.WriteString(r.plainText(c))
+		}
+	}
+	return builder.String()
+}
+
+func isExternal(destination string) bool {
+	parsed, err := url.Parse(destination)
+	return err == nil && parsed.Scheme != ""
+}
+
+// headingSlug builds the anchor GitHub generates for a heading
+func headingSlug(title string) string {
+	var builder strings.Builder
+	for _, char := range strings.ToLower(title) {
+		switch {
+		case char == ' ' || char == '-':
+			builder.WriteRune('-')
+		case char == '_' || (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') || char > 127:
+			builder.WriteRune(char)
+		}
+	}
+	return builder.String()
+}
+
+// escapeText resolves the backslash escapes and entity references of
+// markdown text before escaping it as XHTML, so that &copy; is not published
+// as &amp;copy;
+func escapeText(value []byte) []byte {
+	value = util.UnescapePunctuations(value)
+	value = util.ResolveNumericReferences(value)
+	value = util.ResolveEntityNames(value)
+	return util.EscapeHTML(value)
+}
+
+// cdata wraps text in a CDATA section, splitting any nested terminators
+func cdata(value string) string {
+	return "<![CDATA[" + strings.ReplaceAll(value, "]]>", "]]]]><![CDATA[>") + "]]>"
+}
+
+// closeVoidElements makes raw HTML void elements well-formed XHTML
+func closeVoidElements(raw string) string {
+	return voidElementPattern.ReplaceAllString(raw, "<$1$2 />")
+}
@@ -0,0 +1,266 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OpKind identifies how a line changed between two texts
+type OpKind int
+
+const (
+	OpEqual OpKind = iota
+	OpDelete
+	OpInsert
+)
+
+// Op is a single line of an edit script
+type Op struct {
+	Kind OpKind
+	Line string
+	// OldIndex and NewIndex are the zero based positions of the line in the old
+	// and new text; the index of the side the line is absent from is -1
+	OldIndex int
+	NewIndex int
+}
+
+// SplitLines splits text into lines without their line terminators
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Lines computes the shortest edit script turning oldLines into newLines
+// using the linear space variant of Myers' algorithm, which splits the texts
+// at the middle of the edit path and diffs both halves. Within a change, the
+// deleted lines come before the inserted ones.
+func Lines(oldLines, newLines []string) []Op {
+	// Half of the edit path is searched from each end
+	maxEdits := (len(oldLines)+len(newLines)+1)/2 + 1
+	d := &differ{
+		oldLines: oldLines,
+		newLines: newLines,
+		forward:  make([]int, 2*maxEdits+3),
+		backward: make([]int, 2*maxEdits+3),
+		offset:   maxEdits + 1,
+	}
+	d.compare(0, len(oldLines), 0, len(newLines))
+	return groupChanges(d.ops)
+}
+
+// differ holds the state of a diff, forward and backward are the furthest
+// x reached on each diagonal, searching from the start and from the end
+type differ struct {
+	oldLines []string
+	newLines []string
+	forward  []int
+	backward []int
+	offset   int
+	ops      []Op
+}
+
+// compare appends the edit script of oldLines[oldLow:oldHigh] to
+// newLines[newLow:newHigh]
+func (d *differ) compare(oldLow, oldHigh, newLow, newHigh int) {
+	for oldLow < oldHigh && newLow < newHigh && d.oldLines[oldLow] == d.newLines[newLow] {
+		d.equal(oldLow, newLow)
+		oldLow++
+		newLow++
+	}
+	suffix := 0
+	for oldLow < oldHigh-suffix && newLow < newHigh-suffix && d.oldLines[oldHigh-suffix-1] == d.newLines[newHigh-suffix-1] {
+		suffix++
+	}
+	oldHigh -= suffix
+	newHigh -= suffix
+
+	switch {
+	case oldLow == oldHigh:
+		for y := newLow; y < newHigh; y++ {
+			d.ops = append(d.ops, Op{Kind: OpInsert, Line: d.newLines[y], OldIndex: -1, NewIndex: y})
+		}
+	case newLow == newHigh:
+		for x := oldLow; x < oldHigh; x++ {
+			d.ops = append(d.ops, Op{Kind: OpDelete, Line: d.oldLines[x], OldIndex: x, NewIndex: -1})
+		}
+	default:
+		// Both sides are left and differ at both ends, so at least two edits
+		// are needed and each half needs fewer than the whole
+		x, y, u, v := d.middleSnake(oldLow, oldHigh, newLow, newHigh)
+		d.compare(oldLow, x, newLow, y)
+		for ; x < u; x, y = x+1, y+1 {
+			d.equal(x, y)
+		}
+		d.compare(u, oldHigh, v, newHigh)
+	}
+
+	for i := range suffix {
+		d.equal(oldHigh+i, newHigh+i)
+	}
+}
+
+func (d *differ) equal(x, y int) {
+	d.ops = append(d.ops, Op{Kind: OpEqual, Line: d.oldLines[x], OldIndex: x, NewIndex: y})
+}
+
+// middleSnake finds the run of equal lines from (x, y) to (u, v) in the
+// middle of a shortest edit path, searching from both ends until the paths
+// overlap. The backward search runs on the reversed texts.
+func (d *differ) middleSnake(oldLow, oldHigh, newLow, newHigh int) (int, int, int, int) {
+	n, m := oldHigh-oldLow, newHigh-newLow
+	delta := n - m
+	forward, backward, offset := d.forward, d.backward, d.offset
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	for edits := 0; ; edits++ {
+		for k := -edits; k <= edits; k += 2 {
+			var x int
+			if k == -edits || (k != edits && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.oldLines[oldLow+x] == d.newLines[newLow+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			reverse := delta - k
+			if delta%2 != 0 && reverse >= -(edits-1) && reverse <= edits-1 && x+backward[offset+reverse] >= n {
+				return oldLow + startX, newLow + startY, oldLow + x, newLow + y
+			}
+		}
+		for k := -edits; k <= edits; k += 2 {
+			var x int
+			if k == -edits || (k != edits && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.oldLines[oldHigh-x-1] == d.newLines[newHigh-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			diagonal := delta - k
+			if delta%2 == 0 && diagonal >= -edits && diagonal <= edits && x+forward[offset+diagonal] >= n {
+				return oldHigh - x, newHigh - y, oldHigh - startX, newHigh - startY
+			}
+		}
+	}
+}
+
+// groupChanges moves the deleted lines of every change before its inserted
+// lines, the middle snake search can interleave them
+func groupChanges(ops []Op) []Op {
+	for start := 0; start < len(ops); {
+		if ops[start].Kind == OpEqual {
+			start++
+			continue
+		}
+		end := start
+		for end < len(ops) && ops[end].Kind != OpEqual {
+			end++
+		}
+		slices.SortStableFunc(ops[start:end], func(a, b Op) int {
+			return int(a.Kind) - int(b.Kind)
+		})
+		start = end
+	}
+	return ops
+}
+
+// Unified renders the differences between oldText and newText as a unified
+// diff with the given number of context lines. It returns an empty string when
+// both texts are identical.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	ops := Lines(SplitLines(oldText), SplitLines(newText))
+
+	var builder strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].Kind == OpEqual {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk until there are more than 2*context equal lines in a row
+		hunkStart := max(start-context, 0)
+		end := start
+		for end < len(ops) {
+			if ops[end].Kind != OpEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == OpEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = run
+		}
+
+		if builder.Len() == 0 {
+			fmt.Fprintf(&builder, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(&builder, ops[hunkStart:end])
+		start = end
+	}
+	return builder.String()
+}
+
+func writeHunk(builder *strings.Builder, hunk []Op) {
+	oldStart, newStart := -1, -1
+	oldCount, newCount := 0, 0
+	for _, op := range hunk {
+		if op.Kind != OpInsert {
+			if oldStart == -1 {
+				oldStart = op.OldIndex
+			}
+			oldCount++
+		}
+		if op.Kind != OpDelete {
+			if newStart == -1 {
+				newStart = op.NewIndex
+			}
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, op := range hunk {
+		switch op.Kind {
+		case OpEqual:
+			builder.WriteString(" ")
+		case OpDelete:
+			builder.WriteString("-")
+		case OpInsert:
+			builder.WriteString("+")
+		}
+		builder.WriteString(op.Line)
+		builder.WriteString("\n")
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", max(start, 0))
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
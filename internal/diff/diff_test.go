@@ -0,0 +1,151 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// lcsLength is the length of the longest common subsequence, the number of
+// equal lines of a shortest edit script
+func lcsLength(a, b []string) int {
+	previous := make([]int, len(b)+1)
+	for i := range a {
+		current := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				current[j+1] = previous[j] + 1
+			} else {
+				current[j+1] = max(previous[j+1], current[j])
+			}
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// checkScript verifies that ops turns oldLines into newLines with as few
+// edits as possible and that the indexes are right
+func checkScript(t *testing.T, oldLines, newLines []string, ops []Op) {
+	t.Helper()
+	var gotOld, gotNew []string
+	equal := 0
+	for _, op := range ops {
+		switch op.Kind {
+		case OpEqual:
+			equal++
+			if op.OldIndex != len(gotOld) || op.NewIndex != len(gotNew) {
+				t.Fatalf("equal %q has indexes %d, %d, want %d, %d", op.Line, op.OldIndex, op.NewIndex, len(gotOld), len(gotNew))
+			}
+			gotOld = append(gotOld, op.Line)
+			gotNew = append(gotNew, op.Line)
+		case OpDelete:
+			if op.OldIndex != len(gotOld) || op.NewIndex != -1 {
+				t.Fatalf("delete %q has indexes %d, %d", op.Line, op.OldIndex, op.NewIndex)
+			}
+			gotOld = append(gotOld, op.Line)
+		case OpInsert:
+			if op.NewIndex != len(gotNew) || op.OldIndex != -1 {
+				t.Fatalf("insert %q has indexes %d, %d", op.Line, op.OldIndex, op.NewIndex)
+			}
+			gotNew = append(gotNew, op.Line)
+		}
+	}
+	if strings.Join(gotOld, "\n") != strings.Join(oldLines, "\n") || strings.Join(gotNew, "\n") != strings.Join(newLines, "\n") {
+		t.Fatalf("script does not turn %q into %q: %+v", oldLines, newLines, ops)
+	}
+	if want := lcsLength(oldLines, newLines); equal != want {
+		t.Fatalf("script keeps %d lines of %q and %q, the longest common subsequence has %d", equal, oldLines, newLines, want)
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		old string
+		new string
+	}{
+		{"", ""},
+		{"", "a\nb\n"},
+		{"a\nb\n", ""},
+		{"a\nb\nc\n", "a\nb\nc\n"},
+		{"a\nb\nc\n", "a\nx\nc\n"},
+		{"a\nb\nc\nd\n", "b\nc\nd\ne\n"},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n"},
+		{"x\ny\n", "a\nb\nc\n"},
+	}
+	for _, test := range tests {
+		oldLines, newLines := SplitLines(test.old), SplitLines(test.new)
+		checkScript(t, oldLines, newLines, Lines(oldLines, newLines))
+	}
+
+	random := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "c", "d"}
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(30))
+		for i := range lines {
+			lines[i] = alphabet[random.Intn(len(alphabet))]
+		}
+		return lines
+	}
+	for range 500 {
+		oldLines, newLines := randomLines(), randomLines()
+		checkScript(t, oldLines, newLines, Lines(oldLines, newLines))
+	}
+}
+
+func TestLinesGroupsChanges(t *testing.T) {
+	ops := Lines(SplitLines("a\nb\nc\nd\n"), SplitLines("a\nx\ny\nd\n"))
+	var kinds []string
+	for _, op := range ops {
+		kinds = append(kinds, fmt.Sprintf("%d:%s", op.Kind, op.Line))
+	}
+	want := []string{"0:a", "1:b", "1:c", "2:x", "2:y", "0:d"}
+	if strings.Join(kinds, " ") != strings.Join(want, " ") {
+		t.Errorf("ops = %v, want %v", kinds, want)
+	}
+}
+
+func TestLinesMemory(t *testing.T) {
+	// Two documents without a line in common need the most edits, a frontier
+	// per edit would take gigabytes
+	oldLines := make([]string, 5000)
+	newLines := make([]string, 5000)
+	for i := range oldLines {
+		oldLines[i] = fmt.Sprintf("old %d", i)
+		newLines[i] = fmt.Sprintf("new %d", i)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := Lines(oldLines, newLines)
+	runtime.ReadMemStats(&after)
+
+	if len(ops) != 10000 {
+		t.Errorf("got %d ops, want 10000", len(ops))
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+		t.Errorf("diffing 5000 lines allocated %d MiB", allocated>>20)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n", 3); got != "" {
+		t.Errorf("Unified of equal texts = %q", got)
+	}
+	got := Unified("old.md", "new.md", "1\n2\n3\n4\n5\n6\n7\n8\n9\n", "1\n2\n3\nfour\n5\n6\n7\n8\n9\nten\n", 1)
+	want := `--- old.md
++++ new.md
+@@ -3,3 +3,3 @@
+ 3
+-4
++four
+ 5
+@@ -9 +9,2 @@
+ 9
++ten
+`
+	if got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+)
+
+// MergeLabels names the sides of a merge in conflict markers
+type MergeLabels struct {
+	Ours   string
+	Theirs string
+}
+
+// hunk replaces base[Start:End] with Lines
+type hunk struct {
+	Start int
+	End   int
+	Lines []string
+}
+
+// Merge3 performs a line based three-way merge of ours and theirs, which were
+// both derived from base. Changes made by only one side are applied, identical
+// changes are applied once, and overlapping changes are written between
+// conflict markers. It returns the merged text and the number of conflicts.
+func Merge3(base, ours, theirs string, labels MergeLabels) (string, int) {
+	baseLines := SplitLines(base)
+	ourHunks := hunks(baseLines, SplitLines(ours))
+	theirHunks := hunks(baseLines, SplitLines(theirs))
+
+	var merged []string
+	conflicts := 0
+	cursor := 0
+	for len(ourHunks) > 0 || len(theirHunks) > 0 {
+		// Start a cluster with the earliest hunk and absorb every hunk from
+		// either side that touches it
+		var ourCluster, theirCluster []hunk
+		var start, end int
+		if len(theirHunks) == 0 || (len(ourHunks) > 0 && ourHunks[0].Start <= theirHunks[0].Start) {
+			start, end = ourHunks[0].Start, ourHunks[0].End
+		} else {
+			start, end = theirHunks[0].Start, theirHunks[0].End
+		}
+		for {
+			if len(ourHunks) > 0 && ourHunks[0].Start <= end {
+				ourCluster = append(ourCluster, ourHunks[0])
+				end = max(end, ourHunks[0].End)
+				ourHunks = ourHunks[1:]
+				continue
+			}
+			if len(theirHunks) > 0 && theirHunks[0].Start <= end {
+				theirCluster = append(theirCluster, theirHunks[0])
+				end = max(end, theirHunks[0].End)
+				theirHunks = theirHunks[1:]
+				continue
+			}
+			break
+		}
+
+		merged = append(merged, baseLines[cursor:start]...)
+		oursVersion := apply(baseLines, start, end, ourCluster)
+		theirsVersion := apply(baseLines, start, end, theirCluster)
+		switch {
+		case len(theirCluster) == 0:
+			merged = append(merged, oursVersion...)
+		case len(ourCluster) == 0:
+			merged = append(merged, theirsVersion...)
+		case slices.Equal(oursVersion, theirsVersion):
+			merged = append(merged, oursVersion...)
+		default:
+			conflicts++
+			merged = append(merged, "<<<<<<< "+labels.Ours)
+			merged = append(merged, oursVersion...)
+			merged = append(merged, "=======")
+			merged = append(merged, theirsVersion...)
+			merged = append(merged, ">>>>>>> "+labels.Theirs)
+		}
+		cursor = end
+	}
+	merged = append(merged, baseLines[cursor:]...)
+
+	if len(merged) == 0 {
+		return "", conflicts
+	}
+	return strings.Join(merged, "\n") + "\n", conflicts
+}
+
+// hunks groups the edit script from base to other into replaced regions of base
+func hunks(base, other []string) []hunk {
+	var result []hunk
+	var current *hunk
+	baseIndex := 0
+	for _, op := range Lines(base, other) {
+		if op.Kind == OpEqual {
+			if current != nil {
+				result = append(result, *current)
+				current = nil
+			}
+			baseIndex++
+			continue
+		}
+		if current == nil {
+			current = &hunk{Start: baseIndex, End: baseIndex}
+		}
+		if op.Kind == OpDelete {
+			baseIndex++
+			current.End = baseIndex
+		} else {
+			current.Lines = append(current.Lines, op.Line)
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	return result
+}
+
+// apply returns base[start:end] with the given hunks applied
+func apply(base []string, start, end int, hunks []hunk) []string {
+	var result []string
+	position := start
+	for _, h := range hunks {
+		result = append(result, base[position:h.Start]...)
+		result = append(result, h.Lines...)
+		position = h.End
+	}
+	return append(result, base[position:end]...)
+}
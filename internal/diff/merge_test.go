@@ -0,0 +1,122 @@
+package diff
+
+import "testing"
+
+var mergeLabels = MergeLabels{Ours: "local", Theirs: "confluence"}
+
+func TestMerge3(t *testing.T) {
+	base := "# Title\n\nIntro\n\n## Setup\n\nInstall it.\n\n## Usage\n\nRun it.\n"
+	tests := []struct {
+		name      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "unchanged",
+			ours:   base,
+			theirs: base,
+			want:   base,
+		},
+		{
+			name:   "only ours changed",
+			ours:   "# Title\n\nIntro\n\n## Setup\n\nInstall it with go install.\n\n## Usage\n\nRun it.\n",
+			theirs: base,
+			want:   "# Title\n\nIntro\n\n## Setup\n\nInstall it with go install.\n\n## Usage\n\nRun it.\n",
+		},
+		{
+			name:   "only theirs changed",
+			ours:   base,
+			theirs: "# Title\n\nIntro\n\n## Setup\n\nInstall it.\n\n## Usage\n\nRun it daily.\n",
+			want:   "# Title\n\nIntro\n\n## Setup\n\nInstall it.\n\n## Usage\n\nRun it daily.\n",
+		},
+		{
+			name:   "separate sections",
+			ours:   "# Title\n\nIntro\n\n## Setup\n\nInstall it with go install.\n\n## Usage\n\nRun it.\n",
+			theirs: "# Title\n\nIntro\n\n## Setup\n\nInstall it.\n\n## Usage\n\nRun it daily.\n",
+			want:   "# Title\n\nIntro\n\n## Setup\n\nInstall it with go install.\n\n## Usage\n\nRun it daily.\n",
+		},
+		{
+			name:   "insertions at both ends",
+			ours:   "Draft\n\n" + base,
+			theirs: base + "\n## FAQ\n",
+			want:   "Draft\n\n" + base + "\n## FAQ\n",
+		},
+		{
+			name:   "one side deletes a section the other left alone",
+			ours:   "# Title\n\nIntro\n\n## Usage\n\nRun it.\n",
+			theirs: "# Title\n\nIntroduction\n\n## Setup\n\nInstall it.\n\n## Usage\n\nRun it.\n",
+			want:   "# Title\n\nIntroduction\n\n## Usage\n\nRun it.\n",
+		},
+		{
+			name:   "identical changes",
+			ours:   "# Title\n\nIntro\n\n## Setup\n\nInstall it twice.\n\n## Usage\n\nRun it.\n",
+			theirs: "# Title\n\nIntro\n\n## Setup\n\nInstall it twice.\n\n## Usage\n\nRun it.\n",
+			want:   "# Title\n\nIntro\n\n## Setup\n\nInstall it twice.\n\n## Usage\n\nRun it.\n",
+		},
+		{
+			name:   "same line changed differently",
+			ours:   "# Title\n\nIntro\n\n## Setup\n\nInstall it with go.\n\n## Usage\n\nRun it.\n",
+			theirs: "# Title\n\nIntro\n\n## Setup\n\nInstall it with brew.\n\n## Usage\n\nRun it.\n",
+			want: "# Title\n\nIntro\n\n## Setup\n\n" +
+				"<<<<<<< local\nInstall it with go.\n=======\nInstall it with brew.\n>>>>>>> confluence\n" +
+				"\n## Usage\n\nRun it.\n",
+			conflicts: 1,
+		},
+		{
+			name: "overlapping edits",
+			// Ours renames Setup and rewrites its text, theirs changes the
+			// text and the heading after it. The rename only touches ours and
+			// is merged, the edits from the text on overlap and conflict.
+			ours:   "# Title\n\nIntro\n\n## Installation\n\nDownload it.\n\n## Usage\n\nRun it.\n",
+			theirs: "# Title\n\nIntro\n\n## Setup\n\nInstall it now.\n## Running\n\nRun it.\n",
+			want: "# Title\n\nIntro\n\n## Installation\n\n" +
+				"<<<<<<< local\nDownload it.\n\n## Usage\n=======\nInstall it now.\n## Running\n>>>>>>> confluence\n" +
+				"\nRun it.\n",
+			conflicts: 1,
+		},
+		{
+			name:   "different insertions at the same place",
+			ours:   base + "\n## FAQ\n",
+			theirs: base + "\n## Support\n",
+			want: base +
+				"<<<<<<< local\n\n## FAQ\n=======\n\n## Support\n>>>>>>> confluence\n",
+			conflicts: 1,
+		},
+		{
+			name:   "two conflicts",
+			ours:   "# Guide\n\nIntro\n\n## Setup\n\nInstall it.\n\n## Usage\n\nRun it once.\n",
+			theirs: "# Manual\n\nIntro\n\n## Setup\n\nInstall it.\n\n## Usage\n\nRun it twice.\n",
+			want: "<<<<<<< local\n# Guide\n=======\n# Manual\n>>>>>>> confluence\n" +
+				"\nIntro\n\n## Setup\n\nInstall it.\n\n## Usage\n\n" +
+				"<<<<<<< local\nRun it once.\n=======\nRun it twice.\n>>>>>>> confluence\n",
+			conflicts: 2,
+		},
+		{
+			name:   "both sides delete everything",
+			ours:   "",
+			theirs: "",
+			want:   "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflicts := Merge3(base, test.ours, test.theirs, mergeLabels)
+			if merged != test.want || conflicts != test.conflicts {
+				t.Errorf("Merge3 = %d conflict(s)\n%s\nwant %d conflict(s)\n%s", conflicts, merged, test.conflicts, test.want)
+			}
+		})
+	}
+}
+
+func TestMerge3EmptyBase(t *testing.T) {
+	merged, conflicts := Merge3("", "# Local\n", "", mergeLabels)
+	if merged != "# Local\n" || conflicts != 0 {
+		t.Errorf("Merge3 = %q with %d conflict(s)", merged, conflicts)
+	}
+	merged, conflicts = Merge3("", "# Local\n", "# Remote\n", mergeLabels)
+	if want := "<<<<<<< local\n# Local\n=======\n# Remote\n>>>>>>> confluence\n"; merged != want || conflicts != 1 {
+		t.Errorf("Merge3 = %q with %d conflict(s), want %q", merged, conflicts, want)
+	}
+}
@@ -1,105 +1,499 @@
 package docmeta
 
 import (
+	"bytes"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/Hasankanso/docli/internal/confluence"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/hints"
 	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/output"
 	"github.com/Hasankanso/docli/internal/spec"
 )
 
 type CreateDocMetaCommand struct {
-	DocMeta  *spec.DocMetaData
+	DocMeta  []*spec.DocMetaData
 	SpecRepo *spec.SpecRepo
 }
 
-func NewCreateDocMetaCommand(NewSpecRepo *spec.SpecRepo, newDocMeta *spec.DocMetaData) *CreateDocMetaCommand {
+func NewCreateDocMetaCommand(NewSpecRepo *spec.SpecRepo, newDocMeta ...*spec.DocMetaData) *CreateDocMetaCommand {
 	return &CreateDocMetaCommand{
 		SpecRepo: NewSpecRepo,
 		DocMeta:  newDocMeta,
 	}
 }
 
-func (cmd *CreateDocMetaCommand) Run() {
+func (cmd *CreateDocMetaCommand) Run() error {
 
 	specExists := cmd.SpecRepo.SpecExists()
 	if !specExists {
-		logger.Error("No documentation configuration found")
-		logger.Info("Please run 'docli init' first to initialize your project")
-		return
+		return errs.SpecNotFound()
 	}
 
-	// Save the updated configuration
-	err := cmd.SpecRepo.AddDocMeta(cmd.DocMeta)
+	// Check every document before adding any, so that a bad batch adds nothing
+	docSpec, err := cmd.SpecRepo.GetSpec()
 	if err != nil {
-		logger.Fatal("Error saving configuration: %v", err)
+		return fmt.Errorf("error reading documentation configuration: %w", err)
+	}
+	ids := map[string]bool{}
+	for _, doc := range docSpec.DocMeta {
+		ids[doc.ID] = true
 	}
+	for _, doc := range cmd.DocMeta {
+		err := doc.Validate()
+		if err != nil {
+			return errs.Validation("invalid document metadata '%s': %w", doc.Name, err)
+		}
+		if ids[doc.ID] {
+			return errs.Validation("document metadata with ID '%s' already exists", doc.ID)
+		}
+		ids[doc.ID] = true
+	}
+
+	for _, doc := range cmd.DocMeta {
+		// Save the updated configuration
+		err := cmd.SpecRepo.AddDocMeta(doc)
+		if err != nil {
+			return fmt.Errorf("error saving configuration: %w", err)
+		}
 
-	logger.Success("Document metadata for '%s' added successfully", cmd.DocMeta.Name)
+		logger.Success("Document metadata for '%s' added successfully", doc.Name)
+	}
+	return nil
 }
 
 type DeleteDocMetaCommand struct {
 	ID       string
 	SpecRepo *spec.SpecRepo
+	// Client deletes the Confluence page of the document when set
+	Client *confluence.ConfluenceClient
+	// DeleteLocal removes the generated markdown file of the document
+	DeleteLocal bool
 }
 
-func NewDeleteDocMetaCommand(NewSpecRepo *spec.SpecRepo, id string) *DeleteDocMetaCommand {
+func NewDeleteDocMetaCommand(NewSpecRepo *spec.SpecRepo, id string, client *confluence.ConfluenceClient, deleteLocal bool) *DeleteDocMetaCommand {
 	return &DeleteDocMetaCommand{
-		SpecRepo: NewSpecRepo,
-		ID:       id,
+		SpecRepo:    NewSpecRepo,
+		ID:          id,
+		Client:      client,
+		DeleteLocal: deleteLocal,
 	}
 }
 
-func (cmd *DeleteDocMetaCommand) Run() {
+func (cmd *DeleteDocMetaCommand) Run() error {
 	specExists := cmd.SpecRepo.SpecExists()
 	if !specExists {
-		logger.Error("No documentation configuration found")
-		logger.Info("Please run 'docli init' first to initialize your project")
-		return
+		return errs.SpecNotFound()
+	}
+
+	doc, err := cmd.SpecRepo.GetDocMeta(cmd.ID)
+	if err != nil {
+		return err
+	}
+	// Children would be left with a parent that no longer exists
+	docMetaList, err := cmd.SpecRepo.GetAllDocMeta()
+	if err != nil {
+		return err
+	}
+	var children []string
+	for _, docMeta := range docMetaList {
+		if docMeta.Parent == cmd.ID {
+			children = append(children, docMeta.ID)
+		}
+	}
+	if len(children) > 0 {
+		return errs.Validation("'%s' is the parent of %s, delete them or give them another parent first", cmd.ID, strings.Join(children, ", "))
+	}
+
+	// Remove the page and file first, so that a failure leaves the entry in
+	// place and the deletion can be retried
+	if cmd.Client != nil {
+		pageID, err := confluence.DeletePage(cmd.SpecRepo, cmd.Client, doc)
+		if err != nil {
+			return fmt.Errorf("error deleting the Confluence page of '%s': %w", doc.Name, err)
+		}
+		if pageID != "" {
+			logger.Info("Deleted Confluence page %s", pageID)
+		} else {
+			logger.Info("'%s' has no Confluence page to delete", doc.Name)
+		}
+	}
+	if cmd.DeleteLocal {
+		docPath := cmd.SpecRepo.DocFilePath(doc)
+		err := os.Remove(docPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error deleting %s: %w", docPath, err)
+		}
+		if err == nil {
+			logger.Info("Deleted %s", docPath)
+		}
 	}
 
 	// Save the updated configuration
-	err := cmd.SpecRepo.RemoveDocMeta(cmd.ID)
+	err = cmd.SpecRepo.RemoveDocMeta(cmd.ID)
 	if err != nil {
-		logger.Fatal("Error deleting document metadata: %v", err)
+		return fmt.Errorf("error deleting document metadata: %w", err)
 	}
 	logger.Success("Document metadata with ID '%s' deleted successfully", cmd.ID)
+	return nil
 }
 
 type ListDocMetaCommand struct {
 	SpecRepo *spec.SpecRepo
+	Output   string
 }
 
-func NewListDocMetaCommand(NewSpecRepo *spec.SpecRepo) *ListDocMetaCommand {
+func NewListDocMetaCommand(NewSpecRepo *spec.SpecRepo, output string) *ListDocMetaCommand {
 	return &ListDocMetaCommand{
 		SpecRepo: NewSpecRepo,
+		Output:   output,
 	}
 }
-func (cmd *ListDocMetaCommand) Run() {
-	logger.Info("Listing document metadata entries")
+func (cmd *ListDocMetaCommand) Run() error {
 	specExists := cmd.SpecRepo.SpecExists()
 	if !specExists {
-		logger.Error("No documentation configuration found")
-		logger.Info("Please run 'docli init' first to initialize your project")
-		return
+		return errs.SpecNotFound()
 	}
 
 	// List the document metadata entries
 	docMetaList, err := cmd.SpecRepo.GetAllDocMeta()
 	if err != nil {
-		logger.Fatal("Error retrieving document metadata: %v", err)
+		return fmt.Errorf("error retrieving document metadata: %w", err)
 	}
 
-	if len(docMetaList) == 0 {
+	if len(docMetaList) == 0 && (cmd.Output == output.FormatTable || cmd.Output == "") {
 		logger.Info("No document metadata entries found")
-		return
+		return nil
+	}
+	if docMetaList == nil {
+		docMetaList = []spec.DocMetaData{}
 	}
 
-	// Print header with tabs
-	fmt.Printf("ID\t\tName\t\t\n")
-	fmt.Printf("--\t\t----\t\t\n")
-
+	table := &output.Table{Columns: []string{"id", "name", "parent", "file_hints", "labels"}}
 	for _, docMeta := range docMetaList {
+		table.AddRow(docMeta.ID, docMeta.Name, docMeta.Parent, strings.Join(docMeta.FileHints, ", "), strings.Join(docMeta.Labels, ", "))
+	}
+	err = output.Write(os.Stdout, cmd.Output, table, docMetaList)
+	if err != nil {
+		return fmt.Errorf("error listing document metadata: %w", err)
+	}
+	return nil
+}
+
+// DocMetaChanges lists the changes to make to a document, nil and empty
+// fields are left alone
+type DocMetaChanges struct {
+	Name             *string
+	Description      *string
+	Parent           *string
+	AddHints         []string
+	RemoveHints      []string
+	AddLabels        []string
+	RemoveLabels     []string
+	SetProperties    map[string]string
+	RemoveProperties []string
+}
+
+// Apply makes the changes to a document
+func (c *DocMetaChanges) Apply(doc *spec.DocMetaData) {
+	// Copy before changing in place, doc may share them with the original
+	doc.FileHints = slices.Clone(doc.FileHints)
+	doc.Labels = slices.Clone(doc.Labels)
+	doc.Properties = maps.Clone(doc.Properties)
 
-		fmt.Printf("%s\t%s\t\t\n", docMeta.ID, docMeta.Name)
+	if c.Name != nil {
+		doc.Name = *c.Name
+	}
+	if c.Description != nil {
+		doc.Description = *c.Description
+	}
+	if c.Parent != nil {
+		doc.Parent = *c.Parent
+	}
+	doc.FileHints = slices.DeleteFunc(doc.FileHints, func(hint string) bool {
+		return slices.Contains(c.RemoveHints, hint)
+	})
+	for _, hint := range c.AddHints {
+		if !slices.Contains(doc.FileHints, hint) {
+			doc.FileHints = append(doc.FileHints, hint)
+		}
+	}
+	if len(doc.FileHints) == 0 {
+		doc.FileHints = nil
+	}
+	doc.RemoveLabels(c.RemoveLabels...)
+	doc.AddLabels(c.AddLabels...)
+	for key, value := range c.SetProperties {
+		if doc.Properties == nil {
+			doc.Properties = map[string]string{}
+		}
+		doc.Properties[key] = value
+	}
+	for _, key := range c.RemoveProperties {
+		delete(doc.Properties, key)
+	}
+	if len(doc.Properties) == 0 {
+		doc.Properties = nil
+	}
+}
+
+type UpdateDocMetaCommand struct {
+	ID       string
+	SpecRepo *spec.SpecRepo
+	Changes  DocMetaChanges
+}
+
+func NewUpdateDocMetaCommand(NewSpecRepo *spec.SpecRepo, id string, changes DocMetaChanges) *UpdateDocMetaCommand {
+	return &UpdateDocMetaCommand{
+		SpecRepo: NewSpecRepo,
+		ID:       id,
+		Changes:  changes,
+	}
+}
+
+func (cmd *UpdateDocMetaCommand) Run() error {
+	specExists := cmd.SpecRepo.SpecExists()
+	if !specExists {
+		return errs.SpecNotFound()
+	}
+
+	return saveDocMeta(cmd.SpecRepo, cmd.ID, func(doc *spec.DocMetaData) error {
+		cmd.Changes.Apply(doc)
+		return doc.Validate()
+	})
+}
+
+type EditDocMetaCommand struct {
+	ID       string
+	SpecRepo *spec.SpecRepo
+	// Edit lets the user change the YAML of the document and returns the
+	// result, it is given the previous validation error when retrying
+	Edit func(content []byte, problem error) ([]byte, error)
+	// Retry is asked whether to edit again after an invalid edit
+	Retry func(problem error) bool
+}
+
+func NewEditDocMetaCommand(NewSpecRepo *spec.SpecRepo, id string, edit func(content []byte, problem error) ([]byte, error), retry func(problem error) bool) *EditDocMetaCommand {
+	return &EditDocMetaCommand{
+		SpecRepo: NewSpecRepo,
+		ID:       id,
+		Edit:     edit,
+		Retry:    retry,
+	}
+}
+
+func (cmd *EditDocMetaCommand) Run() error {
+	specExists := cmd.SpecRepo.SpecExists()
+	if !specExists {
+		return errs.SpecNotFound()
+	}
+
+	doc, err := cmd.SpecRepo.GetDocMeta(cmd.ID)
+	if err != nil {
+		return err
+	}
+	original, err := doc.EditableYAML()
+	if err != nil {
+		return fmt.Errorf("error preparing document metadata for editing: %w", err)
+	}
+
+	content := original
+	var problem error
+	for {
+		content, err = cmd.Edit(content, problem)
+		if err != nil {
+			return fmt.Errorf("error editing document metadata: %w", err)
+		}
+		edited := *doc
+		problem = edited.ApplyEditableYAML(content)
+		if problem == nil {
+			if unchanged, _ := edited.EditableYAML(); bytes.Equal(unchanged, original) {
+				logger.Info("No changes made to '%s'", doc.Name)
+				return nil
+			}
+			break
+		}
+		logger.Error("Invalid document metadata: %v", problem)
+		if cmd.Retry == nil || !cmd.Retry(problem) {
+			return errs.Validation("edit discarded, invalid document metadata: %w", problem)
+		}
+	}
+
+	return saveDocMeta(cmd.SpecRepo, cmd.ID, func(doc *spec.DocMetaData) error {
+		return doc.ApplyEditableYAML(content)
+	})
+}
+
+// saveDocMeta updates a document in spec.json and moves its generated file
+// along when it was renamed
+func saveDocMeta(specRepo *spec.SpecRepo, id string, update func(doc *spec.DocMetaData) error) error {
+	var before, after spec.DocMetaData
+	var problem error
+	err := specRepo.UpdateDocMeta(id, func(doc *spec.DocMetaData) {
+		before = *doc
+		edited := *doc
+		problem = update(&edited)
+		if problem == nil {
+			*doc = edited
+		}
+		after = *doc
+	})
+	if err != nil {
+		return err
+	}
+	if problem != nil {
+		return errs.Validation("invalid document metadata: %w", problem)
+	}
+
+	oldPath, newPath := specRepo.DocFilePath(&before), specRepo.DocFilePath(&after)
+	if oldPath != newPath {
+		if _, err := os.Stat(newPath); err == nil {
+			logger.Warning("%s already exists, %s was not moved", newPath, oldPath)
+		} else if err := os.Rename(oldPath, newPath); err == nil {
+			logger.Info("Moved %s to %s", oldPath, newPath)
+		} else if !os.IsNotExist(err) {
+			logger.Warning("Failed to move %s to %s: %v", oldPath, newPath, err)
+		}
+	}
+	logger.Success("Document metadata for '%s' updated successfully", after.Name)
+	return nil
+}
+
+// DocMetaDetails is everything known about a document
+type DocMetaDetails struct {
+	spec.DocMetaData
+	Hints     []hints.Match  "json:\"hints\""
+	File      string         "json:\"file\""
+	Freshness spec.Freshness "json:\"freshness\""
+}
+
+type ShowDocMetaCommand struct {
+	ID       string
+	SpecRepo *spec.SpecRepo
+	Output   string
+}
+
+func NewShowDocMetaCommand(NewSpecRepo *spec.SpecRepo, id, output string) *ShowDocMetaCommand {
+	return &ShowDocMetaCommand{
+		SpecRepo: NewSpecRepo,
+		ID:       id,
+		Output:   output,
+	}
+}
+
+func (cmd *ShowDocMetaCommand) Run() error {
+	specExists := cmd.SpecRepo.SpecExists()
+	if !specExists {
+		return errs.SpecNotFound()
+	}
+
+	doc, err := cmd.SpecRepo.GetDocMeta(cmd.ID)
+	if err != nil {
+		return err
+	}
+
+	details := DocMetaDetails{
+		DocMetaData: *doc,
+		File:        cmd.SpecRepo.DocFilePath(doc),
+	}
+	fileSet, err := hints.Resolve(doc.FileHints)
+	if err != nil {
+		return fmt.Errorf("error resolving the file hints of '%s': %w", doc.Name, err)
+	}
+	details.Hints = fileSet.Hints
+	details.Freshness, err = cmd.SpecRepo.CheckFreshness(doc)
+	if err != nil {
+		return fmt.Errorf("error checking the sources of '%s': %w", doc.Name, err)
+	}
+
+	err = output.Write(os.Stdout, cmd.Output, detailsTable(details), details)
+	if err != nil {
+		return fmt.Errorf("error showing document metadata: %w", err)
+	}
+	return nil
+}
+
+// detailsTable lists the details of a document as field and value rows
+func detailsTable(details DocMetaDetails) *output.Table {
+	table := &output.Table{}
+	field := func(name, format string, args ...interface{}) {
+		table.AddRow(name, fmt.Sprintf(format, args...))
+	}
+
+	field("ID", "%s", details.ID)
+	field("Name", "%s", details.Name)
+	if details.Description != "" {
+		field("Description", "%s", details.Description)
+	}
+	if details.Parent != "" {
+		field("Parent", "%s", details.Parent)
+	}
+	if len(details.Labels) > 0 {
+		field("Labels", "%s", strings.Join(details.Labels, ", "))
+	}
+	for _, key := range details.PropertyKeys() {
+		field("Property", "%s = %s", key, details.Properties[key])
+	}
+
+	if len(details.Hints) == 0 {
+		field("File hints", "none")
+	}
+	for _, hint := range details.Hints {
+		status := "file"
+		switch {
+		case hint.Problem != "":
+			status = hint.Problem
+		case !hint.Exists:
+			status = "matches nothing"
+		case hint.Exclude:
+			status = fmt.Sprintf("leaves out %d file(s)", len(hint.Files))
+		case hint.Glob:
+			status = fmt.Sprintf("glob, %d file(s)", len(hint.Files))
+		case hint.IsDir:
+			status = fmt.Sprintf("folder, %d file(s)", len(hint.Files))
+		}
+		field("File hint", "%s (%s)", hint.Hint, status)
+	}
+
+	freshness := details.Freshness
+	if freshness.Generated {
+		field("File", "%s (generated %s)", details.File, freshness.GeneratedAt.Local().Format(time.DateTime))
+	} else {
+		field("File", "%s (not generated yet)", details.File)
+	}
+	switch {
+	case !freshness.Generated:
+		field("Status", "stale, the document was never generated")
+	case freshness.Stale:
+		field("Status", "stale, %s changed %s", freshness.NewestSource, freshness.NewestSourceAt.Local().Format(time.DateTime))
+	default:
+		field("Status", "up to date")
+	}
+
+	if details.Targets == nil || (details.Targets.Confluence == nil && details.Targets.Readme == nil) {
+		field("Targets", "not synced yet")
+	}
+	if details.Targets != nil && details.Targets.Confluence != nil {
+		target := details.Targets.Confluence
+		field("Confluence", "page %s in space %s", target.PageID, target.SpaceKey)
+		if target.ParentPageID != "" {
+			field("  Parent page", "%s", target.ParentPageID)
+		}
+		if !target.LastSyncedAt.IsZero() {
+			field("  Last synced", "%s (version %d)", target.LastSyncedAt.Local().Format(time.DateTime), target.LastSyncedVersion)
+		}
+		if len(target.Attachments) > 0 {
+			field("  Attachments", "%d", len(target.Attachments))
+		}
+	}
+	if details.Targets != nil && details.Targets.Readme != nil {
+		field("Readme", "anchor #%s", details.Targets.Readme.Anchor)
 	}
+	return table
 }
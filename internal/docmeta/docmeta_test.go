@@ -0,0 +1,40 @@
+package docmeta
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+func TestDeleteRefusesParents(t *testing.T) {
+	dir := t.TempDir()
+	specRepo := &spec.SpecRepo{
+		SpecFilePath:     filepath.Join(dir, "spec.md"),
+		SpecJsonFilePath: filepath.Join(dir, "spec.json"),
+	}
+	err := specRepo.Save(&spec.DocSpec{DocMeta: []spec.DocMetaData{
+		{ID: "guide", Name: "Guide", Description: "How to use it"},
+		{ID: "api", Name: "API", Description: "The endpoints", Parent: "guide"},
+	}})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	err = NewDeleteDocMetaCommand(specRepo, "guide", nil, false).Run()
+	if errs.KindOf(err) != errs.KindValidation {
+		t.Fatalf("deleting a parent = %v, want a validation error", err)
+	}
+	if _, err := specRepo.GetDocMeta("guide"); err != nil {
+		t.Errorf("refused delete removed the parent: %v", err)
+	}
+
+	// Once the child is gone the parent can be deleted
+	for _, id := range []string{"api", "guide"} {
+		err = NewDeleteDocMetaCommand(specRepo, id, nil, false).Run()
+		if err != nil {
+			t.Fatalf("deleting %s: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/Hasankanso/docli/internal/confluence"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/hints"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/output"
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+// Severities of a finding, errors make doctor fail
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a problem doctor found in the project
+type Finding struct {
+	Severity string "json:\"severity\""
+	// Check names the part of the project the finding is about, e.g. hints
+	Check   string "json:\"check\""
+	Subject string "json:\"subject,omitempty\""
+	Message string "json:\"message\""
+}
+
+type DoctorCommand struct {
+	SpecRepo   *spec.SpecRepo
+	ConfigRepo *config.ConfigRepo
+	// Profile is the config profile the Confluence settings are taken from
+	Profile string
+	// Prompts is the source the local prompts are compared with, nil skips
+	// the prompt checks
+	Prompts prompts.Source
+	// Offline skips connecting to Confluence
+	Offline bool
+	// Strict makes warnings fail doctor too
+	Strict bool
+	// Fix regenerates spec.md when it does not match spec.json
+	Fix    bool
+	Output string
+
+	findings []Finding
+}
+
+func NewDoctorCommand(specRepo *spec.SpecRepo, configRepo *config.ConfigRepo, profile string, promptsSource prompts.Source, offline, strict, fix bool, output string) *DoctorCommand {
+	return &DoctorCommand{
+		SpecRepo:   specRepo,
+		ConfigRepo: configRepo,
+		Profile:    profile,
+		Prompts:    promptsSource,
+		Offline:    offline,
+		Strict:     strict,
+		Fix:        fix,
+		Output:     output,
+	}
+}
+
+func (cmd *DoctorCommand) Run() error {
+	if !cmd.SpecRepo.SpecExists() {
+		return errs.SpecNotFound()
+	}
+	docSpec, err := cmd.SpecRepo.GetSpec()
+	if err != nil {
+		cmd.report(SeverityError, "spec", cmd.SpecRepo.SpecJsonFilePath, "cannot be read: %v", err)
+	} else {
+		cmd.checkSpec(docSpec)
+		cmd.checkDocMeta(docSpec)
+		cmd.checkHints(docSpec)
+		cmd.checkGeneratedDocs(docSpec)
+		cmd.checkPrompts()
+		cmd.checkConfluence(docSpec)
+	}
+
+	findings := cmd.findings
+	if findings == nil {
+		findings = []Finding{}
+	}
+	table := &output.Table{Columns: []string{"severity", "check", "subject", "message"}}
+	for _, finding := range findings {
+		table.AddRow(finding.Severity, finding.Check, finding.Subject, finding.Message)
+	}
+	// The table is left out when empty, json and yaml always get a list
+	if len(findings) > 0 || (cmd.Output != output.FormatTable && cmd.Output != "") {
+		err = output.Write(os.Stdout, cmd.Output, table, findings)
+		if err != nil {
+			return fmt.Errorf("error writing the findings: %w", err)
+		}
+	}
+
+	failures, warnings := 0, 0
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			failures++
+		} else {
+			warnings++
+		}
+	}
+	switch {
+	case failures > 0 || (cmd.Strict && warnings > 0):
+		return errs.Validation("found %d error(s) and %d warning(s)", failures, warnings)
+	case warnings > 0:
+		logger.Warning("Found %d warning(s)", warnings)
+	default:
+		logger.Success("No problems found")
+	}
+	return nil
+}
+
+func (cmd *DoctorCommand) report(severity, check, subject, format string, args ...interface{}) {
+	cmd.findings = append(cmd.findings, Finding{
+		Severity: severity,
+		Check:    check,
+		Subject:  subject,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// checkSpec makes sure spec.md was generated from the current spec.json
+func (cmd *DoctorCommand) checkSpec(docSpec *spec.DocSpec) {
+	current, err := cmd.SpecRepo.SpecMarkdownCurrent(docSpec)
+	switch {
+	case err != nil:
+		cmd.report(SeverityError, "spec", cmd.SpecRepo.SpecFilePath, "%v", err)
+	case !current && cmd.Fix:
+		err = cmd.SpecRepo.Save(docSpec)
+		if err != nil {
+			cmd.report(SeverityError, "spec", cmd.SpecRepo.SpecFilePath, "could not be regenerated: %v", err)
+			return
+		}
+		logger.Info("Regenerated %s from %s", cmd.SpecRepo.SpecFilePath, cmd.SpecRepo.SpecJsonFilePath)
+	case !current:
+		cmd.report(SeverityError, "spec", cmd.SpecRepo.SpecFilePath,
+			"does not match %s, it was edited by hand or is missing; run 'docli doctor --fix' to regenerate it", cmd.SpecRepo.SpecJsonFilePath)
+	}
+}
+
+// checkDocMeta looks for invalid entries, duplicate IDs and names, and
+// documents that share a generated file
+func (cmd *DoctorCommand) checkDocMeta(docSpec *spec.DocSpec) {
+	ids := map[string]int{}
+	names := map[string]int{}
+	files := map[string][]string{}
+	for _, doc := range docSpec.DocMeta {
+		ids[doc.ID]++
+		names[strings.ToLower(doc.Name)]++
+		file := cmd.SpecRepo.DocFilePath(&doc)
+		files[file] = append(files[file], doc.Name)
+
+		if doc.ID == "" {
+			cmd.report(SeverityError, "docmeta", doc.Name, "has no ID")
+		}
+		if err := doc.Validate(); err != nil {
+			cmd.report(SeverityError, "docmeta", subject(doc), "%v", err)
+		}
+		if doc.Parent != "" && docSpec.FindDocMeta(doc.Parent) == nil && !isPageID(doc.Parent) {
+			cmd.report(SeverityError, "docmeta", subject(doc), "parent '%s' is neither a document ID nor a Confluence page ID", doc.Parent)
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(ids)) {
+		if id != "" && ids[id] > 1 {
+			cmd.report(SeverityError, "docmeta", id, "ID is used by %d documents", ids[id])
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		if names[name] > 1 {
+			cmd.report(SeverityError, "docmeta", name, "name is used by %d documents", names[name])
+		}
+	}
+	for _, file := range slices.Sorted(maps.Keys(files)) {
+		// Documents with the same name are reported above
+		docNames := files[file]
+		if len(docNames) > 1 && names[strings.ToLower(docNames[0])] < len(docNames) {
+			cmd.report(SeverityError, "docmeta", file, "is the file of %d documents: %s", len(docNames), strings.Join(docNames, ", "))
+		}
+	}
+}
+
+// checkHints reports the file hints that match nothing
+func (cmd *DoctorCommand) checkHints(docSpec *spec.DocSpec) {
+	for _, doc := range docSpec.DocMeta {
+		if len(doc.FileHints) == 0 {
+			cmd.report(SeverityWarning, "hints", subject(doc), "has no file hints, it will be written without sources")
+			continue
+		}
+		set, err := hints.Resolve(doc.FileHints)
+		if err != nil {
+			cmd.report(SeverityError, "hints", subject(doc), "%v", err)
+			continue
+		}
+		for _, match := range set.Unresolved() {
+			cmd.report(SeverityError, "hints", subject(doc), "'%s' %s", match.Hint, match.Issue())
+		}
+	}
+}
+
+// checkGeneratedDocs reports the documents that were never generated
+func (cmd *DoctorCommand) checkGeneratedDocs(docSpec *spec.DocSpec) {
+	for _, doc := range docSpec.DocMeta {
+		path := cmd.SpecRepo.DocFilePath(&doc)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			cmd.report(SeverityWarning, "docs", subject(doc), "%s was not generated yet", path)
+		}
+	}
+}
+
+// checkPrompts reports the prompts of the source missing from .github/prompts
+func (cmd *DoctorCommand) checkPrompts() {
+	if cmd.Prompts == nil {
+		return
+	}
+	states, err := prompts.Inspect(cmd.Prompts)
+	if err != nil {
+		cmd.report(SeverityWarning, "prompts", cmd.Prompts.Name(), "could not be checked: %v", err)
+		return
+	}
+	for _, state := range states {
+		if state.Status == prompts.StatusMissing {
+			cmd.report(SeverityError, "prompts", state.Name, "is missing from %s, run 'docli prompts update'", prompts.PromptsDir)
+		}
+	}
+}
+
+// checkConfluence makes sure Confluence is configured and reachable when it
+// is a platform of the project
+func (cmd *DoctorCommand) checkConfluence(docSpec *spec.DocSpec) {
+	if !slices.ContainsFunc(docSpec.Platforms, func(platform string) bool {
+		return strings.EqualFold(platform, "confluence")
+	}) {
+		return
+	}
+	settings, err := cmd.ConfigRepo.Confluence(cmd.Profile)
+	if err != nil {
+		cmd.report(SeverityError, "confluence", cmd.Profile, "%v", err)
+		return
+	}
+	if settings.URL == "" || settings.Space == "" {
+		cmd.report(SeverityError, "confluence", "", "no URL or space configured, set them with 'docli config set confluence.url <url>' and 'docli config set confluence.space <key>'")
+		return
+	}
+	if cmd.Offline {
+		return
+	}
+
+	logger.Debug("Connecting to %s...", settings.URL)
+	client, err := confluence.NewConfluenceClient(settings.URL, settings.Username, settings.APIToken)
+	if err != nil {
+		cmd.report(SeverityError, "confluence", settings.URL, "%v", err)
+		return
+	}
+	_, err = client.CurrentUser()
+	if err != nil {
+		cmd.report(SeverityError, "confluence", settings.URL, "cannot connect: %v", err)
+		return
+	}
+	_, err = client.GetSpace(settings.Space)
+	if errors.Is(err, confluence.ErrSpaceNotFound) {
+		cmd.report(SeverityError, "confluence", settings.Space, "space not found")
+	} else if err != nil {
+		cmd.report(SeverityError, "confluence", settings.Space, "cannot access the space: %v", err)
+	}
+}
+
+// subject names a document in a finding
+func subject(doc spec.DocMetaData) string {
+	if doc.ID == "" {
+		return doc.Name
+	}
+	return doc.ID + " (" + doc.Name + ")"
+}
+
+// isPageID tells whether a parent looks like a Confluence page ID
+func isPageID(parent string) bool {
+	return strings.Trim(parent, "0123456789") == ""
+}
@@ -0,0 +1,111 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Hasankanso/docli/internal/config"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+// newDoctorProject saves docs to a spec in a temporary working directory,
+// next to a main.go for the hints to match
+func newDoctorProject(t *testing.T, docs ...spec.DocMetaData) *spec.SpecRepo {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	specRepo := spec.NewSpecRepo()
+	err := specRepo.Save(&spec.DocSpec{Platforms: []string{"readme"}, DocMeta: docs})
+	if err == nil {
+		err = os.WriteFile("main.go", []byte("package main\n"), 0644)
+	}
+	if err != nil {
+		t.Fatalf("creating the project: %v", err)
+	}
+	return specRepo
+}
+
+func runDoctor(specRepo *spec.SpecRepo, fix bool) (*DoctorCommand, error) {
+	configRepo := &config.ConfigRepo{ConfigFilePath: filepath.Join(filepath.Dir(specRepo.SpecJsonFilePath), "config.json")}
+	cmd := NewDoctorCommand(specRepo, configRepo, "", nil, true, false, fix, "json")
+	return cmd, cmd.Run()
+}
+
+func TestDoctorHealthyProject(t *testing.T) {
+	doc := spec.DocMetaData{ID: "arch", Name: "Architecture", FileHints: []string{"main.go"}}
+	specRepo := newDoctorProject(t, doc)
+	err := os.WriteFile(specRepo.DocFilePath(&doc), []byte("# Architecture\n"), 0644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cmd, err := runDoctor(specRepo, false)
+	if err != nil || len(cmd.findings) > 0 {
+		t.Errorf("doctor = %v with findings %+v", err, cmd.findings)
+	}
+}
+
+func TestDoctorFindings(t *testing.T) {
+	specRepo := newDoctorProject(t,
+		spec.DocMetaData{ID: "api", Name: "API", FileHints: []string{"main.go"}},
+		spec.DocMetaData{ID: "api", Name: "API v2", FileHints: []string{"main.go"}},
+		spec.DocMetaData{ID: "guide", Name: "Guide", FileHints: []string{"main.go", "missing/"}},
+		spec.DocMetaData{ID: "howto", Name: "guide", FileHints: []string{"main.go"}, Parent: "nowhere"},
+		spec.DocMetaData{ID: "ops", Name: "Operations"},
+	)
+	err := os.WriteFile(specRepo.SpecFilePath, []byte("# Edited by hand\n"), 0644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cmd, err := runDoctor(specRepo, false)
+	if errs.KindOf(err) != errs.KindValidation {
+		t.Errorf("doctor = %v, want a validation error", err)
+	}
+	for _, want := range []Finding{
+		{Severity: SeverityError, Check: "spec", Subject: specRepo.SpecFilePath},
+		{Severity: SeverityError, Check: "docmeta", Subject: "api"},
+		{Severity: SeverityError, Check: "docmeta", Subject: "guide"},
+		{Severity: SeverityError, Check: "docmeta", Subject: "howto (guide)"},
+		{Severity: SeverityError, Check: "hints", Subject: "guide (Guide)"},
+		{Severity: SeverityWarning, Check: "hints", Subject: "ops (Operations)"},
+		{Severity: SeverityWarning, Check: "docs", Subject: "api (API)"},
+	} {
+		if !slices.ContainsFunc(cmd.findings, func(finding Finding) bool {
+			return finding.Severity == want.Severity && finding.Check == want.Check && finding.Subject == want.Subject
+		}) {
+			t.Errorf("no %s %s finding about %s in %+v", want.Severity, want.Check, want.Subject, cmd.findings)
+		}
+	}
+	for _, finding := range cmd.findings {
+		if finding.Subject == "guide (Guide)" && finding.Check == "hints" && finding.Message != "'missing/' matches nothing in the working tree" {
+			t.Errorf("unresolved hint reported as %q", finding.Message)
+		}
+	}
+}
+
+func TestDoctorFixRegeneratesSpec(t *testing.T) {
+	doc := spec.DocMetaData{ID: "arch", Name: "Architecture", FileHints: []string{"main.go"}}
+	specRepo := newDoctorProject(t, doc)
+	err := os.WriteFile(specRepo.DocFilePath(&doc), []byte("# Architecture\n"), 0644)
+	if err == nil {
+		err = os.Remove(specRepo.SpecFilePath)
+	}
+	if err != nil {
+		t.Fatalf("preparing the project: %v", err)
+	}
+
+	cmd, err := runDoctor(specRepo, true)
+	if err != nil || len(cmd.findings) > 0 {
+		t.Fatalf("doctor --fix = %v with findings %+v", err, cmd.findings)
+	}
+	docSpec, err := specRepo.GetSpec()
+	if err != nil {
+		t.Fatalf("GetSpec: %v", err)
+	}
+	if current, err := specRepo.SpecMarkdownCurrent(docSpec); !current || err != nil {
+		t.Errorf("spec.md is not current after --fix: %v", err)
+	}
+}
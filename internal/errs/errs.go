@@ -0,0 +1,104 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error, every kind has its own exit code
+type Kind int
+
+// Exit codes, documented in 'docli --help'
+const (
+	KindGeneral      Kind = 1
+	KindUsage        Kind = 2
+	KindSpecNotFound Kind = 3
+	KindValidation   Kind = 4
+	KindNotFound     Kind = 5
+	KindConflict     Kind = 6
+	KindNetwork      Kind = 7
+	KindAuth         Kind = 8
+)
+
+// Error is an error of a known kind
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(kind Kind, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap gives err a kind, errors that already have one keep it
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var typed *Error
+	if errors.As(err, &typed) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Usage is returned for invalid flags and arguments
+func Usage(format string, args ...interface{}) error {
+	return newError(KindUsage, format, args...)
+}
+
+// SpecNotFound is returned when the project was not initialized with 'docli init'
+func SpecNotFound() error {
+	return newError(KindSpecNotFound, "no documentation configuration found, please run 'docli init' first to initialize your project")
+}
+
+// Validation is returned for invalid input, configuration or spec entries
+func Validation(format string, args ...interface{}) error {
+	return newError(KindValidation, format, args...)
+}
+
+// NotFound is returned when a document, page or profile does not exist
+func NotFound(format string, args ...interface{}) error {
+	return newError(KindNotFound, format, args...)
+}
+
+// Conflict is returned when changes on both sides keep a sync from
+// overwriting anything
+func Conflict(format string, args ...interface{}) error {
+	return newError(KindConflict, format, args...)
+}
+
+// Network is returned when a remote service cannot be reached
+func Network(format string, args ...interface{}) error {
+	return newError(KindNetwork, format, args...)
+}
+
+// Auth is returned when a remote service rejects the credentials
+func Auth(format string, args ...interface{}) error {
+	return newError(KindAuth, format, args...)
+}
+
+// KindOf returns the kind of err, errors without one are general errors
+func KindOf(err error) Kind {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Kind
+	}
+	return KindGeneral
+}
+
+// ExitCode returns the process exit code for err
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return int(KindOf(err))
+}
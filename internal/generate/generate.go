@@ -0,0 +1,185 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/hints"
+	"github.com/Hasankanso/docli/internal/llm"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/Hasankanso/docli/internal/render"
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+// DefaultPrompt is the prompt documents are generated with
+const DefaultPrompt = "updateDoc"
+
+// systemMessage turns the IDE oriented prompts into a request for a single
+// document
+const systemMessage = `You write project documentation in markdown. You cannot read or write files:
+everything you know about the project is in the message, under "## Document"
+and "## Source Files". Write only the document described under "## Document",
+and reply with its complete markdown content and nothing else.`
+
+// promptOverhead is kept free for the chat format around the messages
+const promptOverhead = 64
+
+type GenerateCommand struct {
+	SpecRepo *spec.SpecRepo
+	// ID selects a single document, all documents are generated when empty
+	ID string
+	// Prompt is the prompt name or file the request is rendered from
+	Prompt string
+	Source prompts.Source
+	Client *llm.Client
+	// ContextTokens is the context window of the model, MaxTokens the part
+	// of it kept for the reply
+	ContextTokens int
+	MaxTokens     int
+	// DryRun prints the request instead of sending it
+	DryRun bool
+}
+
+func NewGenerateCommand(specRepo *spec.SpecRepo, id, prompt string, source prompts.Source, client *llm.Client, contextTokens, maxTokens int, dryRun bool) *GenerateCommand {
+	return &GenerateCommand{
+		SpecRepo:      specRepo,
+		ID:            id,
+		Prompt:        prompt,
+		Source:        source,
+		Client:        client,
+		ContextTokens: contextTokens,
+		MaxTokens:     maxTokens,
+		DryRun:        dryRun,
+	}
+}
+
+func (cmd *GenerateCommand) Run() error {
+	if !cmd.SpecRepo.SpecExists() {
+		return errs.SpecNotFound()
+	}
+	docSpec, err := cmd.SpecRepo.GetSpec()
+	if err != nil {
+		return fmt.Errorf("error reading documentation configuration: %w", err)
+	}
+
+	var docs []*spec.DocMetaData
+	if cmd.ID != "" {
+		doc, err := cmd.SpecRepo.GetDocMeta(cmd.ID)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	} else {
+		for i := range docSpec.DocMeta {
+			docs = append(docs, &docSpec.DocMeta[i])
+		}
+	}
+	if len(docs) == 0 {
+		logger.Info("No document metadata found, add some with 'docli create docmeta'")
+		return nil
+	}
+
+	name, prompt, err := render.ReadPrompt(cmd.Prompt, cmd.Source)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		err := cmd.generate(docSpec, doc, name, prompt)
+		if err != nil {
+			return fmt.Errorf("failed to generate '%s': %w", doc.Name, err)
+		}
+	}
+	if !cmd.DryRun && len(docs) > 1 {
+		logger.Success("Generated %d documents", len(docs))
+	}
+	return nil
+}
+
+func (cmd *GenerateCommand) generate(docSpec *spec.DocSpec, doc *spec.DocMetaData, name, prompt string) error {
+	context, err := render.BuildContext(cmd.SpecRepo, docSpec, doc)
+	if err != nil {
+		return err
+	}
+	rendered, err := render.Render(name, prompt, context)
+	if err != nil {
+		return errs.Validation("%v", err)
+	}
+
+	needed := promptOverhead + llm.EstimateTokens(systemMessage) + llm.EstimateTokens(rendered)
+	budget := cmd.ContextTokens - cmd.MaxTokens - needed
+	if budget < 0 {
+		return errs.Validation("the prompt needs about %d tokens, more than the %d tokens of the context window minus %d for the reply",
+			needed, cmd.ContextTokens, cmd.MaxTokens)
+	}
+	// Tokens are estimated at four bytes each
+	bundle, err := hints.NewBundle(context.Files, budget*4)
+	if err != nil {
+		return err
+	}
+	if len(bundle.Omitted) > 0 {
+		logger.Warning("Left out %d file(s) that do not fit in the context window: %s", len(bundle.Omitted), strings.Join(bundle.Omitted, ", "))
+	}
+	for _, skipped := range context.Skipped {
+		logger.Debug("Left out %s, %s", skipped.Path, skipped.Reason)
+	}
+	sources := bundle.Content
+	if sources == "" {
+		sources = "No source files.\n"
+	}
+	messages := []llm.Message{
+		{Role: "system", Content: systemMessage},
+		{Role: "user", Content: rendered + "\n## Source Files\n\n" + sources},
+	}
+
+	if cmd.DryRun {
+		for _, message := range messages {
+			fmt.Printf("===== %s (about %d tokens) =====\n%s\n", message.Role, llm.EstimateTokens(message.Content), message.Content)
+		}
+		return nil
+	}
+
+	logger.Info("Generating '%s' with %s...", doc.Name, cmd.Client.Model)
+	completion, err := cmd.Client.Complete(messages, cmd.MaxTokens)
+	if err != nil {
+		return err
+	}
+	content := unwrapFence(completion.Content)
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("the model replied with an empty document")
+	}
+	if completion.Truncated {
+		logger.Warning("The reply for '%s' hit the limit of %d tokens and is cut short, raise --max-tokens", doc.Name, cmd.MaxTokens)
+	}
+
+	path := cmd.SpecRepo.DocFilePath(doc)
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	err = os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	logger.Success("Generated %s (%d prompt tokens, %d completion tokens)", path, completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
+	return nil
+}
+
+// unwrapFence removes the code block models like to wrap a whole markdown
+// reply in
+func unwrapFence(content string) string {
+	content = strings.TrimSpace(content)
+	first, rest, found := strings.Cut(content, "\n")
+	if !found || !strings.HasPrefix(first, "```") {
+		return content + "\n"
+	}
+	fence := first[:len(first)-len(strings.TrimLeft(first, "`"))]
+	language := strings.TrimSpace(strings.TrimLeft(first, "`"))
+	if (language != "" && language != "markdown" && language != "md") || !strings.HasSuffix(rest, fence) {
+		return content + "\n"
+	}
+	return strings.TrimSpace(strings.TrimSuffix(rest, fence)) + "\n"
+}
@@ -0,0 +1,122 @@
+package generate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/llm"
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+// newGenerateProject creates a project in a temporary working directory with
+// a document hinting at a small and a large source file
+func newGenerateProject(t *testing.T) *spec.SpecRepo {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"small.go":  "package small\n\n// Answer is small enough to fit\nconst Answer = 42\n",
+		"large.go":  "package large\n\n" + strings.Repeat("// This file is far over the budget\n", 400),
+		"prompt.md": "Write the {{.Name}} document.\n",
+	}
+	for name, content := range files {
+		err := os.WriteFile(name, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	specRepo := spec.NewSpecRepo()
+	err := specRepo.InitSpec([]string{"confluence"})
+	if err == nil {
+		err = specRepo.AddDocMeta(&spec.DocMetaData{ID: "arch", Name: "Architecture", FileHints: []string{"small.go", "large.go"}})
+	}
+	if err != nil {
+		t.Fatalf("creating the spec: %v", err)
+	}
+	return specRepo
+}
+
+// newFakeLLM answers every completion with content and records the user
+// messages it receives
+func newFakeLLM(t *testing.T, content string) (*llm.Client, *[]string) {
+	t.Helper()
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Messages []llm.Message "json:\"messages\""
+		}
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		for _, message := range request.Messages {
+			if message.Role == "user" {
+				received = append(received, message.Content)
+			}
+		}
+		reply, _ := json.Marshal(content)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":` + string(reply) + `},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	client := llm.NewClient(server.URL, "key", "test-model")
+	client.Backoff = time.Millisecond
+	return client, &received
+}
+
+func TestGenerateLeavesOutFilesOverTheBudget(t *testing.T) {
+	specRepo := newGenerateProject(t)
+	client, received := newFakeLLM(t, "```markdown\n# Architecture\n\nGenerated.\n```")
+
+	// The large file is about 3600 tokens, more than what is left of the
+	// context window next to the prompt and the reply
+	err := NewGenerateCommand(specRepo, "arch", "prompt.md", nil, client, 2000, 500, false).Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(*received))
+	}
+	message := (*received)[0]
+	if !strings.HasPrefix(message, "Write the Architecture document.") {
+		t.Errorf("request does not start with the rendered prompt:\n%s", message)
+	}
+	if !strings.Contains(message, "### small.go") || !strings.Contains(message, "const Answer = 42") {
+		t.Errorf("request leaves out the file that fits:\n%s", message)
+	}
+	if strings.Contains(message, "large.go") || strings.Contains(message, "far over the budget") {
+		t.Error("request includes the file that does not fit")
+	}
+	if got := llm.EstimateTokens(systemMessage) + llm.EstimateTokens(message) + promptOverhead; got > 2000-500 {
+		t.Errorf("request takes about %d tokens, more than the %d left for it", got, 2000-500)
+	}
+
+	doc, err := specRepo.GetDocMeta("arch")
+	if err != nil {
+		t.Fatalf("GetDocMeta: %v", err)
+	}
+	content, err := os.ReadFile(specRepo.DocFilePath(doc))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(content) != "# Architecture\n\nGenerated.\n" {
+		t.Errorf("generated document is %q", content)
+	}
+}
+
+func TestGenerateRefusesPromptOverTheBudget(t *testing.T) {
+	specRepo := newGenerateProject(t)
+	client, received := newFakeLLM(t, "# Architecture\n")
+
+	err := NewGenerateCommand(specRepo, "arch", "prompt.md", nil, client, 600, 500, false).Run()
+	if errs.KindOf(err) != errs.KindValidation {
+		t.Errorf("error = %v (kind %v), want a validation error", err, errs.KindOf(err))
+	}
+	if len(*received) > 0 {
+		t.Errorf("sent %d requests for a prompt that does not fit", len(*received))
+	}
+}
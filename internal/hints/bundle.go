@@ -0,0 +1,48 @@
+package hints
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// Bundle is the content of a file set in one markdown text
+type Bundle struct {
+	Content string
+	// Files made it into the bundle, Omitted did not fit
+	Files   []string
+	Omitted []string
+}
+
+// NewBundle concatenates files under a header each, leaving out the files
+// that would take it over limit bytes
+func NewBundle(files []string, limit int) (*Bundle, error) {
+	bundle := &Bundle{}
+	var builder strings.Builder
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		section := "### " + file + "\n\n" + Fence(string(content), strings.TrimPrefix(path.Ext(file), ".")) + "\n\n"
+		if builder.Len()+len(section) > limit {
+			bundle.Omitted = append(bundle.Omitted, file)
+			continue
+		}
+		builder.WriteString(section)
+		bundle.Files = append(bundle.Files, file)
+	}
+	bundle.Content = builder.String()
+	return bundle, nil
+}
+
+// Fence wraps content in a markdown code block, with a fence longer than any
+// backtick run in the content
+func Fence(content, language string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + strings.TrimRight(content, "\n") + "\n" + fence
+}
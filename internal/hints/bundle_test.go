@@ -0,0 +1,36 @@
+package hints
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewBundleKeepsToTheLimit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTree(t, ".", map[string]string{
+		"small.go": "package small\n",
+		"large.go": "package large\n" + strings.Repeat("// filler\n", 100),
+		"tiny.md":  "Tiny\n",
+	})
+
+	// The large file does not fit, the files after it still can
+	bundle, err := NewBundle([]string{"small.go", "large.go", "tiny.md"}, 200)
+	if err != nil {
+		t.Fatalf("NewBundle: %v", err)
+	}
+	if !slices.Equal(bundle.Files, []string{"small.go", "tiny.md"}) || !slices.Equal(bundle.Omitted, []string{"large.go"}) {
+		t.Errorf("bundle has %v and omits %v", bundle.Files, bundle.Omitted)
+	}
+	if len(bundle.Content) > 200 {
+		t.Errorf("bundle is %d bytes, over the limit of 200", len(bundle.Content))
+	}
+	want := "### small.go\n\n```go\npackage small\n```\n\n### tiny.md\n\n```md\nTiny\n```\n\n"
+	if bundle.Content != want {
+		t.Errorf("bundle content = %q, want %q", bundle.Content, want)
+	}
+
+	if _, err := NewBundle([]string{"missing.go"}, 200); err == nil {
+		t.Error("NewBundle of a missing file returned no error")
+	}
+}
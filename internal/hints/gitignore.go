@@ -0,0 +1,139 @@
+package hints
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is a line of a .gitignore file
+type ignoreRule struct {
+	// base is the directory of the .gitignore file, "." for the top
+	base    string
+	pattern string
+	negate  bool
+	dirOnly bool
+	// anchored rules match the path below base, the others match the name
+	// at any depth
+	anchored bool
+}
+
+func (r ignoreRule) match(name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "." {
+		if !strings.HasPrefix(name, r.base+"/") {
+			return false
+		}
+		name = strings.TrimPrefix(name, r.base+"/")
+	}
+	if r.anchored {
+		return matchGlob(r.pattern, name)
+	}
+	return matchGlob(r.pattern, path.Base(name))
+}
+
+// gitignore tells which paths the .gitignore files of the working tree
+// exclude, the files are read as directories are visited
+type gitignore struct {
+	// root is the directory the paths are relative to
+	root  string
+	rules map[string][]ignoreRule
+}
+
+func newGitignore() *gitignore {
+	return &gitignore{root: ".", rules: map[string][]ignoreRule{}}
+}
+
+// Ignored tells whether a file, given relative to root, or one of its parent
+// directories is excluded by the .gitignore files under root
+func Ignored(root, file string) bool {
+	ignore := newGitignore()
+	ignore.root = root
+	return ignore.excluded(filepath.ToSlash(filepath.Clean(file)), false)
+}
+
+// ignored tells whether a path is excluded by a .gitignore file in any of
+// its parent directories, the last matching rule wins like in git
+func (g *gitignore) ignored(name string, isDir bool) bool {
+	ignored := false
+	for _, dir := range parents(name) {
+		for _, rule := range g.load(dir) {
+			if rule.match(name, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// excluded tells whether a path or one of its parent directories is ignored,
+// git does not look inside ignored directories
+func (g *gitignore) excluded(name string, isDir bool) bool {
+	dirs := parents(name)
+	for _, dir := range dirs[1:] {
+		if g.ignored(dir, true) {
+			return true
+		}
+	}
+	return g.ignored(name, isDir)
+}
+
+// parents lists the directories above a path from the top, "." first
+func parents(name string) []string {
+	dirs := []string{"."}
+	dir := path.Dir(name)
+	if dir == "." {
+		return dirs
+	}
+	segments := strings.Split(dir, "/")
+	for i := range segments {
+		dirs = append(dirs, strings.Join(segments[:i+1], "/"))
+	}
+	return dirs
+}
+
+func (g *gitignore) load(dir string) []ignoreRule {
+	if rules, found := g.rules[dir]; found {
+		return rules
+	}
+	var rules []ignoreRule
+	file, err := os.Open(filepath.Join(g.root, filepath.FromSlash(dir), ".gitignore"))
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreRule(dir, scanner.Text()); ok {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	g.rules[dir] = rules
+	return rules
+}
+
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	rule.anchored = strings.Contains(line, "/")
+	rule.pattern = strings.TrimPrefix(line, "/")
+	if rule.pattern == "" || !validGlob(rule.pattern) {
+		return ignoreRule{}, false
+	}
+	return rule, true
+}
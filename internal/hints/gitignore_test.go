@@ -0,0 +1,108 @@
+package hints
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree creates files with their content under dir, names are slash
+// separated
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+}
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		line string
+		want ignoreRule
+		ok   bool
+	}{
+		{line: "", ok: false},
+		{line: "   ", ok: false},
+		{line: "# comment", ok: false},
+		{line: "*.log", want: ignoreRule{base: ".", pattern: "*.log"}, ok: true},
+		{line: "*.log \r", want: ignoreRule{base: ".", pattern: "*.log"}, ok: true},
+		{line: "build/", want: ignoreRule{base: ".", pattern: "build", dirOnly: true}, ok: true},
+		{line: "/vendor", want: ignoreRule{base: ".", pattern: "vendor", anchored: true}, ok: true},
+		{line: "docs/*.tmp", want: ignoreRule{base: ".", pattern: "docs/*.tmp", anchored: true}, ok: true},
+		{line: "!keep.log", want: ignoreRule{base: ".", pattern: "keep.log", negate: true}, ok: true},
+		{line: `\#notes`, want: ignoreRule{base: ".", pattern: "#notes"}, ok: true},
+		{line: `\!bang`, want: ignoreRule{base: ".", pattern: "!bang"}, ok: true},
+		{line: "[broken", ok: false},
+		{line: "/", ok: false},
+	}
+	for _, test := range tests {
+		rule, ok := parseIgnoreRule(".", test.line)
+		if ok != test.ok || (ok && rule != test.want) {
+			t.Errorf("parseIgnoreRule(%q) = %+v, %v, want %+v, %v", test.line, rule, ok, test.want, test.ok)
+		}
+	}
+	if rule, _ := parseIgnoreRule("sub/dir", "*.o"); rule.base != "sub/dir" {
+		t.Errorf("rule of sub/dir/.gitignore has base %q", rule.base)
+	}
+}
+
+func TestGitignore(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":         "*.log\n!keep.log\nbuild/\n/secret.txt\n",
+		"api/.gitignore":     "generated/\n!debug.log\n*.tmp\n",
+		"api/web/.gitignore": "!*.tmp\n",
+	})
+	ignore := newGitignore()
+	ignore.root = root
+
+	tests := []struct {
+		name  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"keep.log", false, false},
+		{"api/app.log", false, true},
+		// A deeper .gitignore overrides the rules above it
+		{"api/debug.log", false, false},
+		{"api/cache.tmp", false, true},
+		{"api/web/cache.tmp", false, false},
+		{"cache.tmp", false, false},
+		{"build", true, true},
+		// Directory rules do not match files
+		{"build", false, false},
+		{"api/build", true, true},
+		// Anchored rules only match below the directory of their .gitignore
+		{"secret.txt", false, true},
+		{"api/secret.txt", false, false},
+		{"api/generated", true, true},
+		{"generated", true, false},
+		{"main.go", false, false},
+	}
+	for _, test := range tests {
+		if got := ignore.ignored(test.name, test.isDir); got != test.want {
+			t.Errorf("ignored(%q, dir %v) = %v, want %v", test.name, test.isDir, got, test.want)
+		}
+	}
+
+	// Files inside an ignored directory are excluded, a negation cannot bring
+	// them back
+	for name, want := range map[string]bool{
+		"build/out.txt":          true,
+		"build/keep.log":         true,
+		"api/generated/types.go": true,
+		"api/handler.go":         false,
+	} {
+		if got := Ignored(root, filepath.FromSlash(name)); got != want {
+			t.Errorf("Ignored(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
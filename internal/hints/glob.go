@@ -0,0 +1,71 @@
+package hints
+
+import (
+	"path"
+	"strings"
+)
+
+// hasGlob tells whether a pattern has wildcards
+func hasGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// validGlob checks the syntax of every segment of a pattern
+func validGlob(pattern string) bool {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// matchGlob matches a slash separated path against a pattern where each
+// segment is a path.Match pattern and ** matches any number of directories,
+// e.g. cmd/**/*.go matches cmd/root.go and cmd/sub/x.go
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range len(parts) + 1 {
+				if matchSegments(pattern, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		matched, err := path.Match(pattern[0], parts[0])
+		if err != nil || !matched {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// globBase returns the directory a pattern can match files under, the
+// segments before the first wildcard
+func globBase(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	var base []string
+	for _, segment := range segments[:len(segments)-1] {
+		if hasGlob(segment) {
+			break
+		}
+		base = append(base, segment)
+	}
+	if len(base) == 0 {
+		return "."
+	}
+	return strings.Join(base, "/")
+}
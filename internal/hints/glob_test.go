@@ -0,0 +1,71 @@
+package hints
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/root.go", false},
+		{"cmd/*.go", "cmd/root.go", true},
+		{"cmd/*.go", "cmd/sub/x.go", false},
+		{"cmd/**/*.go", "cmd/root.go", true},
+		{"cmd/**/*.go", "cmd/sub/deep/x.go", true},
+		{"cmd/**/*.go", "internal/x.go", false},
+		{"**/*_test.go", "x_test.go", true},
+		{"**/*_test.go", "internal/hints/glob_test.go", true},
+		{"**/*_test.go", "internal/hints/glob.go", false},
+		{"cmd/**", "cmd/sub/x.go", true},
+		{"**", "anything/at/all", true},
+		{"internal/**/testdata/*.md", "internal/converter/testdata/tables.md", true},
+		{"internal/**/testdata/*.md", "internal/converter/tables.md", false},
+		{"doc?.md", "doc1.md", true},
+		{"doc?.md", "doc12.md", false},
+		{"[abc].txt", "b.txt", true},
+		{"[abc].txt", "d.txt", false},
+		{"cmd/*.go", "cmd", false},
+	}
+	for _, test := range tests {
+		if got := matchGlob(test.pattern, test.name); got != test.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
+func TestGlobBase(t *testing.T) {
+	tests := map[string]string{
+		"*.go":                     ".",
+		"**/*.go":                  ".",
+		"cmd/*.go":                 "cmd",
+		"cmd/**/*.go":              "cmd",
+		"internal/hints/*.go":      "internal/hints",
+		"internal/*/testdata/*.md": "internal",
+		"docs/file?.md":            "docs",
+	}
+	for pattern, want := range tests {
+		if got := globBase(pattern); got != want {
+			t.Errorf("globBase(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestValidGlob(t *testing.T) {
+	tests := map[string]bool{
+		"cmd/**/*.go": true,
+		"[abc].txt":   true,
+		"docs/[a-z]*": true,
+		"[abc.txt":    false,
+		"cmd/[/x.go":  false,
+		`docs/\`:      false,
+		"plain/path":  true,
+		"**/[!_]*.go": true,
+	}
+	for pattern, want := range tests {
+		if got := validGlob(pattern); got != want {
+			t.Errorf("validGlob(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
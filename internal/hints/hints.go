@@ -0,0 +1,291 @@
+package hints
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/logger"
+)
+
+// MaxFileSize is the size above which files are left out of a file set
+var MaxFileSize int64 = 256 << 10
+
+// binaryProbe is how much of a file is searched for a NUL byte to tell binary
+// files apart
+const binaryProbe = 8000
+
+// Match is what a single file hint resolves to
+type Match struct {
+	Hint string "json:\"hint\""
+	// Exclude is set for hints starting with !, which remove files matched by
+	// the hints before them
+	Exclude bool "json:\"exclude,omitempty\""
+	Glob    bool "json:\"glob,omitempty\""
+	// Exists is set when the hint names an existing path, or its glob
+	// matches at least one file
+	Exists bool "json:\"exists\""
+	IsDir  bool "json:\"is_dir,omitempty\""
+	// Problem tells why a hint cannot be resolved, e.g. an invalid glob
+	Problem string "json:\"problem,omitempty\""
+	// Files are the files the hint matches, or removes for exclusions
+	Files []string "json:\"files,omitempty\""
+}
+
+// Issue tells why a hint does not resolve, empty when it does
+func (m Match) Issue() string {
+	switch {
+	case m.Problem != "":
+		return m.Problem
+	case !m.Exists:
+		return "matches nothing in the working tree"
+	}
+	return ""
+}
+
+// Skipped is a file left out of a file set
+type Skipped struct {
+	Path   string "json:\"path\""
+	Reason string "json:\"reason\""
+}
+
+// FileSet is the files a list of hints resolves to
+type FileSet struct {
+	Hints   []Match   "json:\"hints\""
+	Files   []string  "json:\"files\""
+	Skipped []Skipped "json:\"skipped,omitempty\""
+}
+
+// Unresolved returns the hints that match nothing or are invalid
+func (s *FileSet) Unresolved() []Match {
+	var unresolved []Match
+	for _, match := range s.Hints {
+		if !match.Exists {
+			unresolved = append(unresolved, match)
+		}
+	}
+	return unresolved
+}
+
+// Resolve expands file hints relative to the working directory into files.
+// A hint is a file, a directory whose files are listed recursively, or a
+// glob like cmd/**/*.go, and a hint starting with ! removes the files matched
+// so far. Hints that match nothing or are invalid are kept with Exists unset.
+// Directories and globs leave out the files ignored by .gitignore and hidden
+// files and directories, files named explicitly are always kept. Binary
+// files and files larger than MaxFileSize are skipped.
+func Resolve(fileHints []string) (*FileSet, error) {
+	set := &FileSet{Hints: []Match{}, Files: []string{}}
+	ignore := newGitignore()
+	included := map[string]bool{}
+	skipped := map[string]string{}
+
+	for _, hint := range fileHints {
+		match, err := resolveHint(hint, ignore)
+		if err != nil {
+			return nil, err
+		}
+		if match.Exclude {
+			for file := range included {
+				if excludes(match, file) {
+					delete(included, file)
+					match.Files = append(match.Files, file)
+				}
+			}
+			slices.Sort(match.Files)
+			set.Hints = append(set.Hints, match)
+			continue
+		}
+
+		var files []string
+		for _, file := range match.Files {
+			if _, found := skipped[file]; found {
+				continue
+			}
+			if !included[file] {
+				reason, err := skipReason(file)
+				if err != nil {
+					return nil, err
+				}
+				if reason != "" {
+					logger.Debug("Leaving out %s, %s", file, reason)
+					skipped[file] = reason
+					continue
+				}
+				included[file] = true
+			}
+			files = append(files, file)
+		}
+		match.Files = files
+		set.Hints = append(set.Hints, match)
+	}
+
+	for file := range included {
+		set.Files = append(set.Files, file)
+	}
+	slices.Sort(set.Files)
+	for file, reason := range skipped {
+		set.Skipped = append(set.Skipped, Skipped{Path: file, Reason: reason})
+	}
+	slices.SortFunc(set.Skipped, func(a, b Skipped) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return set, nil
+}
+
+// Check resolves a single hint, e.g. to validate it as it is entered
+func Check(hint string) (Match, error) {
+	set, err := Resolve([]string{hint})
+	if err != nil {
+		return Match{}, err
+	}
+	return set.Hints[0], nil
+}
+
+// Normalize cleans a hint into the slash separated form it is matched with,
+// keeping a leading !
+func Normalize(hint string) string {
+	hint = strings.TrimSpace(hint)
+	prefix := ""
+	if strings.HasPrefix(hint, "!") {
+		prefix, hint = "!", strings.TrimSpace(hint[1:])
+	}
+	if hint == "" {
+		return prefix
+	}
+	return prefix + path.Clean(filepath.ToSlash(hint))
+}
+
+func resolveHint(hint string, ignore *gitignore) (Match, error) {
+	match := Match{Hint: hint}
+	pattern := Normalize(hint)
+	if strings.HasPrefix(pattern, "!") {
+		match.Exclude = true
+		pattern = pattern[1:]
+	}
+	if pattern == "" {
+		match.Problem = "empty hint"
+		return match, nil
+	}
+	if path.IsAbs(pattern) || pattern == ".." || strings.HasPrefix(pattern, "../") {
+		match.Problem = "outside of the project"
+		return match, nil
+	}
+
+	if hasGlob(pattern) {
+		match.Glob = true
+		if !validGlob(pattern) {
+			match.Problem = "invalid glob"
+			return match, nil
+		}
+		files, err := walk(globBase(pattern), ignore, func(file string) bool {
+			return matchGlob(pattern, file)
+		})
+		if err != nil {
+			return match, err
+		}
+		match.Exists = len(files) > 0
+		if !match.Exclude {
+			match.Files = files
+		}
+		return match, nil
+	}
+
+	info, err := os.Stat(pattern)
+	if os.IsNotExist(err) {
+		return match, nil
+	}
+	if err != nil {
+		return match, fmt.Errorf("failed to read %s: %w", pattern, err)
+	}
+	match.Exists = true
+	match.IsDir = info.IsDir()
+	if match.Exclude {
+		return match, nil
+	}
+	if !match.IsDir {
+		match.Files = []string{pattern}
+		return match, nil
+	}
+	match.Files, err = walk(pattern, ignore, func(string) bool { return true })
+	return match, err
+}
+
+// excludes tells whether an exclusion removes a file
+func excludes(match Match, file string) bool {
+	pattern := strings.TrimPrefix(Normalize(match.Hint), "!")
+	if match.Glob {
+		return matchGlob(pattern, file)
+	}
+	return pattern == "." || file == pattern || strings.HasPrefix(file, pattern+"/")
+}
+
+// walk lists the files under dir that keep returns true for, leaving out
+// .gitignore'd paths and hidden files and directories, e.g. .env
+func walk(dir string, ignore *gitignore, keep func(file string) bool) ([]string, error) {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) || (err == nil && !info.IsDir()) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	if dir != "." && ignore.excluded(dir, true) {
+		return nil, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			logger.Debug("Leaving out %s: %v", name, err)
+			return nil
+		}
+		name = filepath.ToSlash(name)
+		if entry.IsDir() {
+			if name != dir && (strings.HasPrefix(entry.Name(), ".") || ignore.ignored(name, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") || ignore.ignored(name, false) || !keep(name) {
+			return nil
+		}
+		files = append(files, name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files of %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// skipReason tells why a file is left out, empty when it is not
+func skipReason(file string) (string, error) {
+	handle, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer handle.Close()
+	info, err := handle.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if info.Size() > MaxFileSize {
+		return fmt.Sprintf("larger than %d KiB", MaxFileSize>>10), nil
+	}
+	probe := make([]byte, binaryProbe)
+	n, err := io.ReadFull(handle, probe)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if bytes.IndexByte(probe[:n], 0) >= 0 {
+		return "binary", nil
+	}
+	return "", nil
+}
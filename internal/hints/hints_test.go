@@ -0,0 +1,150 @@
+package hints
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newHintsProject creates a working tree in a temporary working directory
+func newHintsProject(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	writeTree(t, ".", map[string]string{
+		".gitignore":       "*.log\n",
+		".env":             "TOKEN=secret",
+		"main.go":          "package main\n",
+		"cmd/root.go":      "package cmd\n",
+		"cmd/root_test.go": "package cmd\n",
+		"cmd/.env":         "TOKEN=secret",
+		"cmd/debug.log":    "log",
+		"cmd/sub/x.go":     "package sub\n",
+		".secret/key.go":   "package secret\n",
+		"logo.png":         "\x89PNG\x00\x00",
+	})
+}
+
+func TestResolve(t *testing.T) {
+	newHintsProject(t)
+	tests := []struct {
+		name  string
+		hints []string
+		want  []string
+	}{
+		{
+			name:  "directory",
+			hints: []string{"cmd"},
+			want:  []string{"cmd/root.go", "cmd/root_test.go", "cmd/sub/x.go"},
+		},
+		{
+			name:  "exclusion after the files",
+			hints: []string{"cmd", "!**/*_test.go"},
+			want:  []string{"cmd/root.go", "cmd/sub/x.go"},
+		},
+		{
+			name:  "exclusion before the files",
+			hints: []string{"!**/*_test.go", "cmd"},
+			want:  []string{"cmd/root.go", "cmd/root_test.go", "cmd/sub/x.go"},
+		},
+		{
+			name:  "file added back after an exclusion",
+			hints: []string{"cmd", "!cmd/sub", "cmd/sub/x.go"},
+			want:  []string{"cmd/root.go", "cmd/root_test.go", "cmd/sub/x.go"},
+		},
+		{
+			name:  "glob",
+			hints: []string{"**/*.go", "!cmd"},
+			want:  []string{"main.go"},
+		},
+		{
+			name:  "whole tree leaves out hidden, ignored and binary files",
+			hints: []string{"."},
+			want:  []string{"cmd/root.go", "cmd/root_test.go", "cmd/sub/x.go", "main.go"},
+		},
+		{
+			name:  "files named explicitly are kept",
+			hints: []string{"cmd/.env", "cmd/debug.log"},
+			want:  []string{"cmd/.env", "cmd/debug.log"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set, err := Resolve(test.hints)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if !slices.Equal(set.Files, test.want) {
+				t.Errorf("Resolve(%q) = %v, want %v", test.hints, set.Files, test.want)
+			}
+		})
+	}
+}
+
+func TestResolveReportsHints(t *testing.T) {
+	newHintsProject(t)
+	set, err := Resolve([]string{"cmd", "!cmd/sub", "missing/", "docs/[x", "../outside", "logo.png"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	issues := map[string]string{}
+	for _, match := range set.Hints {
+		issues[match.Hint] = match.Issue()
+	}
+	want := map[string]string{
+		"cmd":        "",
+		"!cmd/sub":   "",
+		"missing/":   "matches nothing in the working tree",
+		"docs/[x":    "invalid glob",
+		"../outside": "outside of the project",
+		"logo.png":   "",
+	}
+	for hint, issue := range want {
+		if issues[hint] != issue {
+			t.Errorf("hint %q has issue %q, want %q", hint, issues[hint], issue)
+		}
+	}
+	if excluded := set.Hints[1].Files; !slices.Equal(excluded, []string{"cmd/sub/x.go"}) {
+		t.Errorf("!cmd/sub removed %v", excluded)
+	}
+	if len(set.Skipped) != 1 || set.Skipped[0].Path != "logo.png" || set.Skipped[0].Reason != "binary" {
+		t.Errorf("Skipped = %+v, want logo.png as binary", set.Skipped)
+	}
+	if unresolved := set.Unresolved(); len(unresolved) != 3 {
+		t.Errorf("Unresolved = %+v, want the 3 hints with an issue", unresolved)
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	t.Chdir(t.TempDir())
+	defer func(size int64) { MaxFileSize = size }(MaxFileSize)
+	MaxFileSize = 16 << 10
+	writeTree(t, ".", map[string]string{
+		"empty.txt":  "",
+		"text.md":    "# Title\n",
+		"binary.bin": "abc\x00def",
+		"late.bin":   strings.Repeat("a", binaryProbe) + "\x00",
+		"large.txt":  strings.Repeat("a", 16<<10+1),
+		"limit.txt":  strings.Repeat("a", 16<<10),
+	})
+	tests := map[string]string{
+		"empty.txt":  "",
+		"text.md":    "",
+		"binary.bin": "binary",
+		// Only the start of a file is searched for NUL bytes
+		"late.bin":  "",
+		"large.txt": "larger than 16 KiB",
+		"limit.txt": "",
+	}
+	for file, want := range tests {
+		reason, err := skipReason(file)
+		if err != nil {
+			t.Fatalf("skipReason(%s): %v", file, err)
+		}
+		if reason != want {
+			t.Errorf("skipReason(%s) = %q, want %q", file, reason, want)
+		}
+	}
+	if _, err := skipReason("missing.txt"); err == nil {
+		t.Error("skipReason of a missing file returned no error")
+	}
+}
@@ -0,0 +1,210 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+)
+
+// DefaultURL is the OpenAI API, used when no URL is configured
+const DefaultURL = "https://api.openai.com/v1"
+
+// Message is a chat message, Role is system, user or assistant
+type Message struct {
+	Role    string "json:\"role\""
+	Content string "json:\"content\""
+}
+
+type completionRequest struct {
+	Model     string    "json:\"model\""
+	Messages  []Message "json:\"messages\""
+	MaxTokens int       "json:\"max_tokens,omitempty\""
+}
+
+type completionResponse struct {
+	Choices []struct {
+		Message      Message "json:\"message\""
+		FinishReason string  "json:\"finish_reason\""
+	} "json:\"choices\""
+	Usage Usage "json:\"usage\""
+}
+
+// Usage is the token count the API reports for a completion
+type Usage struct {
+	PromptTokens     int "json:\"prompt_tokens\""
+	CompletionTokens int "json:\"completion_tokens\""
+}
+
+// Completion is the reply of the model
+type Completion struct {
+	Content string
+	// Truncated is set when the reply hit the max tokens
+	Truncated bool
+	Usage     Usage
+}
+
+// Client calls the chat completions endpoint of an OpenAI compatible API
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+	// MaxRetries is how many times a request is retried after a rate limit,
+	// a server error or a network error
+	MaxRetries int
+	// Backoff is the wait before the first retry, it doubles every retry
+	Backoff time.Duration
+	// MaxWait caps the wait between retries. A server asking for a longer
+	// wait with Retry-After fails the request instead.
+	MaxWait time.Duration
+}
+
+func NewClient(baseURL, apiKey, model string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		APIKey:     apiKey,
+		Model:      model,
+		HTTPClient: &http.Client{Timeout: 5 * time.Minute},
+		MaxRetries: 3,
+		Backoff:    2 * time.Second,
+		MaxWait:    time.Minute,
+	}
+}
+
+// Complete sends the messages and returns the reply, retrying transient
+// failures
+func (c *Client) Complete(messages []Message, maxTokens int) (*Completion, error) {
+	body, err := json.Marshal(completionRequest{Model: c.Model, Messages: messages, MaxTokens: maxTokens})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the request: %w", err)
+	}
+
+	wait := c.Backoff
+	for attempt := 0; ; attempt++ {
+		completion, retryAfter, err := c.complete(body)
+		if err == nil {
+			return completion, nil
+		}
+		var transient *transientError
+		if !errors.As(err, &transient) || attempt >= c.MaxRetries {
+			return nil, err
+		}
+		if retryAfter > c.MaxWait {
+			return nil, fmt.Errorf("%w, the server asked to retry in %s", err, retryAfter)
+		}
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		logger.Warning("%v, retrying in %s (%d/%d)", err, wait, attempt+1, c.MaxRetries)
+		time.Sleep(wait)
+		wait = min(wait*2, c.MaxWait)
+	}
+}
+
+// transientError is a failure worth retrying
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// complete makes a single request, it returns how long the server asked to
+// wait before retrying, if it did
+func (c *Client) complete(body []byte) (*Completion, time.Duration, error) {
+	endpoint := c.BaseURL + "/chat/completions"
+	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, errs.Validation("invalid LLM URL %s: %v", c.BaseURL, err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		request.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	logger.Debug("POST %s (%d bytes)", endpoint, len(body))
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		var urlError *url.Error
+		if errors.As(err, &urlError) {
+			return nil, 0, &transientError{errs.Network("failed to reach %s: %v", c.BaseURL, urlError.Err)}
+		}
+		return nil, 0, errs.Network("failed to reach %s: %v", c.BaseURL, err)
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, &transientError{errs.Network("failed to read the response of %s: %v", c.BaseURL, err)}
+	}
+
+	switch {
+	case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+		return nil, 0, errs.Auth("the LLM API refused the API key: %s", apiMessage(response, content))
+	case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500:
+		return nil, retryAfter(response), &transientError{errs.Network("the LLM API answered %s", apiMessage(response, content))}
+	case response.StatusCode != http.StatusOK:
+		return nil, 0, fmt.Errorf("the LLM API answered %s", apiMessage(response, content))
+	}
+
+	var completion completionResponse
+	err = json.Unmarshal(content, &completion)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse the response of %s: %w", c.BaseURL, err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, 0, fmt.Errorf("the LLM API returned no choices")
+	}
+	choice := completion.Choices[0]
+	return &Completion{
+		Content:   choice.Message.Content,
+		Truncated: choice.FinishReason == "length",
+		Usage:     completion.Usage,
+	}, 0, nil
+}
+
+// apiMessage describes a failed response with the error message of the API
+// when it has one
+func apiMessage(response *http.Response, content []byte) string {
+	var body struct {
+		Error struct {
+			Message string "json:\"message\""
+		} "json:\"error\""
+	}
+	if json.Unmarshal(content, &body) == nil && body.Error.Message != "" {
+		return fmt.Sprintf("%s: %s", response.Status, body.Error.Message)
+	}
+	return response.Status
+}
+
+// retryAfter reads the Retry-After header given in seconds
+func retryAfter(response *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// EstimateTokens approximates the token count of text, about four characters
+// per token for English and code
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
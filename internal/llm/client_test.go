@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/errs"
+)
+
+// reply is a canned response of the fake API
+type reply struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+const okBody = `{"choices":[{"message":{"role":"assistant","content":"# Doc"},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`
+
+// newFakeAPI serves the replies in order, repeating the last one, and
+// returns a client for it that retries without waiting
+func newFakeAPI(t *testing.T, replies ...reply) (*Client, *[]completionRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []completionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s %s with authorization %q", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var request completionRequest
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		requests = append(requests, request)
+
+		next := replies[min(len(requests), len(replies))-1]
+		if next.retryAfter != "" {
+			w.Header().Set("Retry-After", next.retryAfter)
+		}
+		w.WriteHeader(next.status)
+		w.Write([]byte(next.body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL+"/v1/", "key", "test-model")
+	client.Backoff = time.Millisecond
+	return client, &requests
+}
+
+func TestCompleteRetriesTransientFailures(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		client, requests := newFakeAPI(t,
+			reply{status: status, body: `{"error":{"message":"try again"}}`},
+			reply{status: status},
+			reply{status: http.StatusOK, body: okBody},
+		)
+		completion, err := client.Complete([]Message{{Role: "user", Content: "Write the doc"}}, 100)
+		if err != nil {
+			t.Fatalf("status %d: Complete: %v", status, err)
+		}
+		if completion.Content != "# Doc" || completion.Usage.PromptTokens != 12 || completion.Truncated {
+			t.Errorf("status %d: completion = %+v", status, completion)
+		}
+		if len(*requests) != 3 {
+			t.Errorf("status %d: sent %d requests, want 3", status, len(*requests))
+		}
+		if got := (*requests)[2]; got.Model != "test-model" || got.MaxTokens != 100 || got.Messages[0].Content != "Write the doc" {
+			t.Errorf("status %d: retried request = %+v", status, got)
+		}
+	}
+}
+
+func TestCompleteGivesUpAfterMaxRetries(t *testing.T) {
+	client, requests := newFakeAPI(t, reply{status: http.StatusBadGateway, body: `{"error":{"message":"upstream down"}}`})
+	_, err := client.Complete([]Message{{Role: "user", Content: "Write the doc"}}, 0)
+	if err == nil {
+		t.Fatal("Complete succeeded against a failing server")
+	}
+	if len(*requests) != client.MaxRetries+1 {
+		t.Errorf("sent %d requests, want %d", len(*requests), client.MaxRetries+1)
+	}
+	if errs.KindOf(err) != errs.KindNetwork || !strings.Contains(err.Error(), "upstream down") {
+		t.Errorf("error = %v (kind %v), want a network error with the API message", err, errs.KindOf(err))
+	}
+}
+
+func TestCompleteHonoursRetryAfter(t *testing.T) {
+	client, requests := newFakeAPI(t,
+		reply{status: http.StatusTooManyRequests, retryAfter: "1"},
+		reply{status: http.StatusOK, body: okBody},
+	)
+	start := time.Now()
+	_, err := client.Complete([]Message{{Role: "user", Content: "Write the doc"}}, 0)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, the server asked for 1s", elapsed)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("sent %d requests, want 2", len(*requests))
+	}
+}
+
+func TestCompleteRefusesLongRetryAfter(t *testing.T) {
+	client, requests := newFakeAPI(t,
+		reply{status: http.StatusTooManyRequests, retryAfter: "3600"},
+		reply{status: http.StatusOK, body: okBody},
+	)
+	start := time.Now()
+	_, err := client.Complete([]Message{{Role: "user", Content: "Write the doc"}}, 0)
+	if err == nil {
+		t.Fatal("Complete waited for an hour long Retry-After")
+	}
+	if elapsed := time.Since(start); elapsed > client.MaxWait {
+		t.Errorf("gave up after %s", elapsed)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("sent %d requests, want 1", len(*requests))
+	}
+	if errs.KindOf(err) != errs.KindNetwork || !strings.Contains(err.Error(), "1h0m0s") {
+		t.Errorf("error = %v (kind %v), want a network error naming the wait", err, errs.KindOf(err))
+	}
+}
+
+func TestCompleteAuthFailures(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		client, requests := newFakeAPI(t, reply{status: status, body: `{"error":{"message":"invalid api key"}}`})
+		_, err := client.Complete([]Message{{Role: "user", Content: "Write the doc"}}, 0)
+		if errs.KindOf(err) != errs.KindAuth {
+			t.Errorf("status %d: error = %v (kind %v), want an auth error", status, err, errs.KindOf(err))
+		}
+		if err != nil && !strings.Contains(err.Error(), "invalid api key") {
+			t.Errorf("status %d: error %q does not carry the API message", status, err)
+		}
+		if len(*requests) != 1 {
+			t.Errorf("status %d: sent %d requests, auth failures must not be retried", status, len(*requests))
+		}
+	}
+}
@@ -1,52 +1,161 @@
 package logger
 
 import (
-	"log"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 )
 
+// Level is the minimum severity of the messages a logger prints
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// LevelEnv names the environment variable that sets the log level
+const LevelEnv = "DOCLI_LOG_LEVEL"
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel reads a level name, "warning" is accepted for warn
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		return LevelWarn, nil
+	}
+	for level, levelName := range levelNames {
+		if levelName == name {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level '%s', expected debug, info, warn or error", name)
+}
+
 // Logger provides consistent logging throughout the application
 type Logger struct {
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
-	warnLogger  *log.Logger
+	level Level
+	sink  Sink
 }
 
 // NewLogger creates a new logger instance
 func NewLogger() *Logger {
 	return &Logger{
-		infoLogger:  log.New(os.Stdout, "", 0),
-		errorLogger: log.New(os.Stderr, "ERROR: ", 0),
-		warnLogger:  log.New(os.Stdout, "WARNING: ", 0),
+		level: LevelInfo,
+		sink:  NewTextSink(os.Stdout),
 	}
 }
 
+// SetLevel changes the minimum severity of printed messages
+func (l *Logger) SetLevel(level Level) {
+	l.level = level
+}
+
+// Level returns the minimum severity of printed messages
+func (l *Logger) Level() Level {
+	return l.level
+}
+
+// SetSink changes where messages are written
+func (l *Logger) SetSink(sink Sink) {
+	l.sink = sink
+}
+
+func (l *Logger) log(level Level, success bool, fields Fields, format string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+	l.sink.Write(Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Success: success,
+		Message: fmt.Sprintf(format, args...),
+		Fields:  fields,
+	})
+}
+
+// Debug logs details that help tracing what docli does
+func (l *Logger) Debug(format string, args ...interface{}) {
+	l.log(LevelDebug, false, nil, format, args...)
+}
+
 // Info logs informational messages
 func (l *Logger) Info(format string, args ...interface{}) {
-	l.infoLogger.Printf(format, args...)
+	l.log(LevelInfo, false, nil, format, args...)
+}
+
+// Prompt asks the user for input, prompts are shown at every level and in
+// every format
+func (l *Logger) Prompt(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stdout, format+"\n", args...)
 }
 
 // Error logs error messages
 func (l *Logger) Error(format string, args ...interface{}) {
-	l.errorLogger.Printf(format, args...)
+	l.log(LevelError, false, nil, format, args...)
 }
 
 // Warning logs warning messages
 func (l *Logger) Warning(format string, args ...interface{}) {
-	l.warnLogger.Printf(format, args...)
+	l.log(LevelWarn, false, nil, format, args...)
 }
 
 // Success logs success messages
 func (l *Logger) Success(format string, args ...interface{}) {
-	l.infoLogger.Printf("SUCCESS: "+format, args...)
+	l.log(LevelInfo, true, nil, format, args...)
 }
 
 // Fatal logs error and exits the program
 func (l *Logger) Fatal(format string, args ...interface{}) {
-	l.errorLogger.Printf(format, args...)
+	l.log(LevelError, false, nil, format, args...)
 	os.Exit(1)
 }
 
+// With returns a logger that adds structured fields to every message
+func (l *Logger) With(fields Fields) *FieldLogger {
+	return &FieldLogger{logger: l, fields: fields}
+}
+
+// FieldLogger logs messages with structured fields, which only show up in
+// the JSON log format
+type FieldLogger struct {
+	logger *Logger
+	fields Fields
+}
+
+func (f *FieldLogger) Debug(format string, args ...interface{}) {
+	f.logger.log(LevelDebug, false, f.fields, format, args...)
+}
+
+func (f *FieldLogger) Info(format string, args ...interface{}) {
+	f.logger.log(LevelInfo, false, f.fields, format, args...)
+}
+
+func (f *FieldLogger) Warning(format string, args ...interface{}) {
+	f.logger.log(LevelWarn, false, f.fields, format, args...)
+}
+
+func (f *FieldLogger) Error(format string, args ...interface{}) {
+	f.logger.log(LevelError, false, f.fields, format, args...)
+}
+
+func (f *FieldLogger) Success(format string, args ...interface{}) {
+	f.logger.log(LevelInfo, true, f.fields, format, args...)
+}
+
 // Infof is an alias for Info for backwards compatibility
 func (l *Logger) Infof(format string, args ...interface{}) {
 	l.Info(format, args...)
@@ -61,10 +170,30 @@ func (l *Logger) Errorf(format string, args ...interface{}) {
 var GlobalLogger = NewLogger()
 
 // Package level convenience functions
+func SetLevel(level Level) {
+	GlobalLogger.SetLevel(level)
+}
+
+func SetSink(sink Sink) {
+	GlobalLogger.SetSink(sink)
+}
+
+func With(fields Fields) *FieldLogger {
+	return GlobalLogger.With(fields)
+}
+
+func Debug(format string, args ...interface{}) {
+	GlobalLogger.Debug(format, args...)
+}
+
 func Info(format string, args ...interface{}) {
 	GlobalLogger.Info(format, args...)
 }
 
+func Prompt(format string, args ...interface{}) {
+	GlobalLogger.Prompt(format, args...)
+}
+
 func Error(format string, args ...interface{}) {
 	GlobalLogger.Error(format, args...)
 }
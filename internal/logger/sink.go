@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// Log formats the --log-format flag accepts
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Fields are structured details of a log entry, e.g. the document or page it
+// is about
+type Fields map[string]interface{}
+
+// Entry is a single log message
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Success bool
+	Message string
+	Fields  Fields
+}
+
+// Sink writes log entries somewhere
+type Sink interface {
+	Write(entry Entry)
+}
+
+// NewSink returns the sink for a log format. out receives the info and
+// warning messages of the text format, commands printing machine output pass
+// stderr to keep stdout parseable.
+func NewSink(format string, out io.Writer) (Sink, error) {
+	switch format {
+	case FormatText, "":
+		return NewTextSink(out), nil
+	case FormatJSON:
+		return NewJSONSink(os.Stderr), nil
+	}
+	return nil, fmt.Errorf("unknown log format '%s', expected %s or %s", format, FormatText, FormatJSON)
+}
+
+// TextSink writes human readable messages, info and warnings to its output
+// and everything else to stderr. Fields are left out, the message already
+// mentions what matters to a reader.
+type TextSink struct {
+	debugLogger *log.Logger
+	infoLogger  *log.Logger
+	errorLogger *log.Logger
+	warnLogger  *log.Logger
+}
+
+func NewTextSink(out io.Writer) *TextSink {
+	return &TextSink{
+		// Debug output goes to stderr to keep stdout clean for machine output
+		debugLogger: log.New(os.Stderr, "DEBUG: ", 0),
+		infoLogger:  log.New(out, "", 0),
+		errorLogger: log.New(os.Stderr, "ERROR: ", 0),
+		warnLogger:  log.New(out, "WARNING: ", 0),
+	}
+}
+
+func (s *TextSink) Write(entry Entry) {
+	switch {
+	case entry.Success:
+		s.infoLogger.Print("SUCCESS: " + entry.Message)
+	case entry.Level == LevelDebug:
+		s.debugLogger.Print(entry.Message)
+	case entry.Level == LevelInfo:
+		s.infoLogger.Print(entry.Message)
+	case entry.Level == LevelWarn:
+		s.warnLogger.Print(entry.Message)
+	default:
+		s.errorLogger.Print(entry.Message)
+	}
+}
+
+// JSONSink writes one JSON object per entry and line, with the level,
+// message, timestamp and fields of the entry
+type JSONSink struct {
+	encoder *json.Encoder
+}
+
+func NewJSONSink(w io.Writer) *JSONSink {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &JSONSink{encoder: encoder}
+}
+
+func (s *JSONSink) Write(entry Entry) {
+	object := make(map[string]interface{}, len(entry.Fields)+4)
+	for key, value := range entry.Fields {
+		object[key] = value
+	}
+	object["time"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	object["level"] = entry.Level.String()
+	object["message"] = entry.Message
+	if entry.Success {
+		object["success"] = true
+	}
+	s.encoder.Encode(object)
+}
@@ -0,0 +1,19 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTextSinkWritesToItsOutput(t *testing.T) {
+	var out bytes.Buffer
+	sink := NewTextSink(&out)
+	sink.Write(Entry{Level: LevelInfo, Message: "Reading spec"})
+	sink.Write(Entry{Level: LevelWarn, Message: "Skipping the prompt checks"})
+	sink.Write(Entry{Level: LevelInfo, Success: true, Message: "Done"})
+
+	want := "Reading spec\nWARNING: Skipping the prompt checks\nSUCCESS: Done\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,127 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats the global --output flag accepts
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatCSV   = "csv"
+)
+
+// Formats lists the output formats in the order they are documented
+var Formats = []string{FormatTable, FormatJSON, FormatYAML, FormatCSV}
+
+// Table is the tabular form of command output, used by the table and csv
+// formats. A table without columns is written without a header row.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// AddRow appends a row to the table
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// Write renders command output in the given format: the table for table and
+// csv, value for json and yaml. value is encoded with its json field names
+// in both cases.
+func Write(w io.Writer, format string, table *Table, value interface{}) error {
+	switch format {
+	case FormatTable, "":
+		return WriteTable(w, table)
+	case FormatCSV:
+		return writeCSV(w, table)
+	case FormatJSON:
+		return WriteJSON(w, value)
+	case FormatYAML:
+		return WriteYAML(w, value)
+	}
+	return UnknownFormat(format, Formats...)
+}
+
+// UnknownFormat is the error for an output format a command does not support
+func UnknownFormat(format string, supported ...string) error {
+	return fmt.Errorf("unknown output format '%s', expected one of %s", format, strings.Join(supported, ", "))
+}
+
+// WriteTable writes a table with aligned columns
+func WriteTable(w io.Writer, table *Table) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(table.Columns) > 0 {
+		header := make([]string, len(table.Columns))
+		for i, column := range table.Columns {
+			header[i] = strings.ToUpper(column)
+		}
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+	}
+	for _, row := range table.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// Tabs and newlines would break the alignment
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+	}
+	return writer.Flush()
+}
+
+func writeCSV(w io.Writer, table *Table) error {
+	writer := csv.NewWriter(w)
+	if len(table.Columns) > 0 {
+		writer.Write(table.Columns)
+	}
+	writer.WriteAll(table.Rows)
+	return writer.Error()
+}
+
+// WriteJSON writes value as indented JSON
+func WriteJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// WriteYAML writes value as YAML. It goes through JSON so that the json
+// field names and omitempty rules of the spec types apply.
+func WriteYAML(w io.Writer, value interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	// Decoding into a node keeps the field order of the JSON
+	var node yaml.Node
+	err = yaml.Unmarshal(content, &node)
+	if err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&node)
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle drops the flow style JSON decodes into, strings keep their
+// quotes only where YAML needs them
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,128 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/output"
+)
+
+// Actions a sync can plan for a single document
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionPull     = "pull"
+	ActionMerge    = "merge"
+	ActionMove     = "move"
+	ActionDelete   = "delete"
+	ActionNone     = "none"
+	ActionOutdated = "outdated"
+	ActionConflict = "conflict"
+	ActionMissing  = "missing"
+	ActionError    = "error"
+)
+
+// actionOrder lists the actions in the order they are summarised
+var actionOrder = []string{ActionCreate, ActionUpdate, ActionPull, ActionMerge, ActionMove, ActionDelete, ActionNone, ActionOutdated, ActionConflict, ActionMissing, ActionError}
+
+// FormatText is the text form of a plan, the table output format is an alias
+const FormatText = "text"
+
+// Plan lists the changes a sync to one platform would make, without making
+// any of them
+type Plan struct {
+	Platform string   "json:\"platform\""
+	SpaceKey string   "json:\"space_key,omitempty\""
+	Actions  []Action "json:\"actions\""
+}
+
+// Action is the planned change for a single document or page
+type Action struct {
+	Action string "json:\"action\""
+	DocID  string "json:\"doc_id,omitempty\""
+	Title  string "json:\"title\""
+	PageID string "json:\"page_id,omitempty\""
+	Detail string "json:\"detail,omitempty\""
+	// Diff is a unified diff of the document body, empty when nothing changes
+	Diff string "json:\"diff,omitempty\""
+}
+
+// HasChanges reports whether applying the plan would change anything
+func (p *Plan) HasChanges() bool {
+	for _, action := range p.Actions {
+		switch action.Action {
+		case ActionCreate, ActionUpdate, ActionPull, ActionMerge, ActionMove, ActionDelete:
+			return true
+		}
+	}
+	return false
+}
+
+// Summary counts the planned actions, e.g. "1 create, 2 none"
+func (p *Plan) Summary() string {
+	counts := map[string]int{}
+	for _, action := range p.Actions {
+		counts[action.Action]++
+	}
+	var parts []string
+	for _, action := range actionOrder {
+		if counts[action] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[action], action))
+		}
+	}
+	if len(parts) == 0 {
+		return "no documents"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Write renders plans in the given output format
+func Write(w io.Writer, format string, plans []*Plan) error {
+	switch format {
+	case FormatText, output.FormatTable, "":
+		return writeText(w, plans)
+	case output.FormatJSON:
+		return output.WriteJSON(w, plans)
+	case output.FormatYAML:
+		return output.WriteYAML(w, plans)
+	}
+	return output.UnknownFormat(format, output.FormatTable, output.FormatJSON, output.FormatYAML)
+}
+
+func writeText(w io.Writer, plans []*Plan) error {
+	var builder strings.Builder
+	for i, p := range plans {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		if p.SpaceKey != "" {
+			fmt.Fprintf(&builder, "Plan for %s space '%s':\n", p.Platform, p.SpaceKey)
+		} else {
+			fmt.Fprintf(&builder, "Plan for %s:\n", p.Platform)
+		}
+
+		for _, action := range p.Actions {
+			fmt.Fprintf(&builder, "  %-9s %s", action.Action, action.Title)
+			if action.PageID != "" {
+				fmt.Fprintf(&builder, " (page %s)", action.PageID)
+			}
+			if action.Detail != "" {
+				fmt.Fprintf(&builder, ": %s", action.Detail)
+			}
+			builder.WriteString("\n")
+		}
+
+		for _, action := range p.Actions {
+			if action.Diff != "" {
+				builder.WriteString("\n")
+				builder.WriteString(action.Diff)
+			}
+		}
+
+		fmt.Fprintf(&builder, "\nPlan: %s\n", p.Summary())
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
@@ -0,0 +1,118 @@
+package prompts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/logger"
+)
+
+// LockFileName is the file in .github/prompts that records the installed
+// version of every prompt
+const LockFileName = "prompts.lock.json"
+
+// Lock records where the prompts were installed from and the hash of each
+// prompt as installed, which tells local modifications apart from upstream
+// changes
+type Lock struct {
+	Source  string               "json:\"source,omitempty\""
+	Prompts map[string]LockEntry "json:\"prompts\""
+}
+
+// LockEntry is the installed version of a prompt
+type LockEntry struct {
+	Hash        string    "json:\"hash\""
+	InstalledAt time.Time "json:\"installed_at\""
+}
+
+func lockPath() string {
+	return filepath.Join(PromptsDir, LockFileName)
+}
+
+// basePath is where the content of a prompt as installed is kept, the base
+// of a three-way update
+func basePath(name string) string {
+	return filepath.Join(PromptsDir, ".docli", name)
+}
+
+// LoadLock reads the lock file, a missing lock file is an empty lock
+func LoadLock() (*Lock, error) {
+	lock := &Lock{Prompts: map[string]LockEntry{}}
+	content, err := os.ReadFile(lockPath())
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", lockPath(), err)
+	}
+	err = json.Unmarshal(content, lock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", lockPath(), err)
+	}
+	if lock.Prompts == nil {
+		lock.Prompts = map[string]LockEntry{}
+	}
+	return lock, nil
+}
+
+// Save writes the lock file
+func (l *Lock) Save() error {
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", LockFileName, err)
+	}
+	logger.Debug("Writing %s with %d prompt(s)", lockPath(), len(l.Prompts))
+	err = os.WriteFile(lockPath(), append(content, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockPath(), err)
+	}
+	return nil
+}
+
+// Record marks content as the installed version of a prompt and keeps it as
+// the base of the next update
+func (l *Lock) Record(name string, content []byte) error {
+	path := basePath(name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	err = os.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write the base of %s: %w", name, err)
+	}
+	l.Prompts[name] = LockEntry{Hash: contentHash(content), InstalledAt: time.Now().UTC()}
+	return nil
+}
+
+// Base returns the content of a prompt as installed. The boolean is false
+// when the prompt is not in the lock or its base was lost.
+func (l *Lock) Base(name string) ([]byte, bool, error) {
+	entry, found := l.Prompts[name]
+	if !found {
+		return nil, false, nil
+	}
+	content, err := os.ReadFile(basePath(name))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read the base of %s: %w", name, err)
+	}
+	if contentHash(content) != entry.Hash {
+		logger.Debug("Ignoring the base of %s, it does not match the lock file", name)
+		return nil, false, nil
+	}
+	return content, true, nil
+}
+
+// contentHash returns the hex encoded SHA-256 of content
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,18 +1,40 @@
 package prompts
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/Hasankanso/docli/internal/errs"
 	"github.com/Hasankanso/docli/internal/logger"
 )
 
+// Sources the --prompts-source flag accepts besides registries, directories
+// and URLs
+const (
+	SourceEmbedded = "embedded"
+	SourceGitHub   = "github"
+)
+
+// PromptSuffix ends the name of every prompt file
+const PromptSuffix = ".prompt.md"
+
+// PromptsDir is where the prompt files are copied to
+var PromptsDir = filepath.Join(".github", "prompts")
+
+// Embedded holds the prompt files shipped with the binary, set by main
+var Embedded fs.FS
+
 // GitHubFile represents a file in a GitHub directory
 type GitHubFile struct {
 	Name        string `json:"name"`
@@ -29,55 +51,316 @@ type GitHubFileContent struct {
 	Encoding string `json:"encoding"`
 }
 
-func CopyPromptFiles() error {
+// Source is a place prompt files can be copied from
+type Source interface {
+	// Name is the --prompts-source value of the source, it is recorded in
+	// the lock file
+	Name() string
+	// List returns the names of the prompt files, e.g. syncDoc.prompt.md
+	List() ([]string, error)
+	// Read returns the content of a prompt file
+	Read(name string) ([]byte, error)
+}
+
+// NewSource resolves the value of --prompts-source: default, the name of a
+// registry, embedded, github, a git repository, an http(s) base URL or a
+// local directory. registries are the declared registries by precedence.
+func NewSource(source string, registries []Registry) (Source, error) {
+	if source == SourceDefault || source == "" {
+		layered := &layeredSource{name: SourceDefault}
+		for _, registry := range registries {
+			registrySource, err := newRegistrySource(registry)
+			if err != nil {
+				return nil, err
+			}
+			layered.sources = append(layered.sources, registrySource)
+		}
+		if Embedded != nil {
+			layered.sources = append(layered.sources, &fsSource{name: SourceEmbedded, files: Embedded})
+		}
+		return layered, nil
+	}
+	if registry, found := FindRegistry(registries, source); found {
+		return newRegistrySource(registry)
+	}
+	return locationSource(source)
+}
+
+func newRegistrySource(registry Registry) (Source, error) {
+	err := registry.Validate()
+	if err != nil {
+		return nil, errs.Wrap(errs.KindValidation, err)
+	}
+	source, err := locationSource(registry.Source)
+	if err != nil {
+		return nil, fmt.Errorf("prompt source '%s': %w", registry.Name, err)
+	}
+	return &registrySource{Source: source, name: registry.Name}, nil
+}
+
+// locationSource returns the source at a location, as opposed to a registry
+func locationSource(source string) (Source, error) {
+	switch {
+	case source == SourceEmbedded:
+		if Embedded == nil {
+			return nil, fmt.Errorf("no prompt files were embedded in this build, use --prompts-source")
+		}
+		return &fsSource{name: SourceEmbedded, files: Embedded}, nil
+	case source == SourceGitHub:
+		return &gitHubSource{}, nil
+	case strings.HasPrefix(source, gitPrefix):
+		return newGitSource(source), nil
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		return &httpSource{baseURL: strings.TrimSuffix(source, "/")}, nil
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, errs.Validation("prompts source '%s' is neither a registry, %s, %s, a git repository, an http(s) URL nor a directory", source, SourceEmbedded, SourceGitHub)
+	}
+	if !info.IsDir() {
+		return nil, errs.Validation("prompts source '%s' is not a directory", source)
+	}
+	return &fsSource{name: source, files: os.DirFS(source)}, nil
+}
+
+// ResolveSource returns the source given with --prompts-source, or the one
+// the prompts were installed from when the flag is empty
+func ResolveSource(source string, registries []Registry) (Source, error) {
+	if source == "" {
+		lock, err := LoadLock()
+		if err != nil {
+			return nil, err
+		}
+		source = lock.Source
+	}
+	return NewSource(source, registries)
+}
+
+// CopyPromptFiles copies the prompt files of a source to .github/prompts and
+// records them in the lock file. Files that already exist are kept, see
+// UpdatePromptsCommand for bringing them up to date.
+func CopyPromptFiles(source Source) error {
 	// Create .github/prompts directory if it doesn't exist
-	promptsDir := filepath.Join(".github", "prompts")
-	err := os.MkdirAll(promptsDir, 0755)
+	err := os.MkdirAll(PromptsDir, 0755)
 	if err != nil {
 		return fmt.Errorf("failed to create .github/prompts directory: %w", err)
 	}
 
-	// GitHub repository information
-	const (
-		apiURL = "https://api.github.com/repos/Hasankanso/docli/contents/.github/prompts"
-	)
+	lock, err := LoadLock()
+	if err != nil {
+		return err
+	}
+	if lock.Source == "" {
+		lock.Source = source.Name()
+	}
 
-	// Get list of all prompt files from GitHub
-	promptFiles, err := getPromptFilesFromGitHub(apiURL)
+	logger.Debug("Copying prompt files from %s", source.Name())
+	names, err := source.List()
 	if err != nil {
 		return fmt.Errorf("failed to get prompt files list: %w", err)
 	}
 
 	// Copy each prompt file if it doesn't exist locally
-	for _, file := range promptFiles {
-		// Only process .prompt.md files
-		if !strings.HasSuffix(file.Name, ".prompt.md") {
-			continue
+	for _, name := range names {
+		localPath := filepath.Join(PromptsDir, name)
+		local, err := os.ReadFile(localPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", localPath, err)
+		}
+		exists := err == nil
+		if exists {
+			if _, tracked := lock.Prompts[name]; tracked {
+				logger.Debug("Keeping existing %s", localPath)
+				continue
+			}
 		}
 
-		localPath := filepath.Join(promptsDir, file.Name)
-		if _, err := os.Stat(localPath); os.IsNotExist(err) {
-			err = fetchFileContentFromGitHub(file.Path, localPath)
-			if err != nil {
-				return fmt.Errorf("failed to fetch %s: %w", file.Name, err)
+		content, err := source.Read(name)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", name, err)
+		}
+		if exists {
+			// An identical file can be tracked from now on
+			if bytes.Equal(local, content) {
+				err = lock.Record(name, content)
+				if err != nil {
+					return err
+				}
 			}
-			logger.Success("Created .github/prompts/%s", file.Name)
+			logger.Debug("Keeping existing %s", localPath)
+			continue
+		}
+		err = os.WriteFile(localPath, content, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write file %s: %w", localPath, err)
 		}
+		err = lock.Record(name, content)
+		if err != nil {
+			return err
+		}
+		logger.Success("Created .github/prompts/%s", name)
 	}
 
-	return nil
+	return lock.Save()
 }
 
-func getPromptFilesFromGitHub(apiURL string) ([]GitHubFile, error) {
+// isPromptFile tells whether a file name is a prompt file
+func isPromptFile(name string) bool {
+	return strings.HasSuffix(name, PromptSuffix)
+}
+
+// fsSource reads prompt files from the top of a file system, either the
+// embedded one or a local directory
+type fsSource struct {
+	name  string
+	files fs.FS
+}
+
+func (s *fsSource) Name() string {
+	return s.name
+}
+
+func (s *fsSource) List() ([]string, error) {
+	entries, err := fs.ReadDir(s.files, ".")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isPromptFile(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (s *fsSource) Read(name string) ([]byte, error) {
+	return fs.ReadFile(s.files, name)
+}
+
+// IndexFileName is the manifest an HTTP prompt source lists its prompts in
+const IndexFileName = "index.json"
+
+// Index is the manifest of an HTTP prompt source
+type Index struct {
+	Prompts []string "json:\"prompts\""
+}
+
+// httpSource fetches prompt files from <base URL>/<name>. A plain HTTP
+// server cannot list a directory, so the prompts are listed in
+// <base URL>/index.json. Without an index the names of the embedded prompt
+// files are fetched.
+type httpSource struct {
+	baseURL string
+	// names caches the index, List is called more than once per command
+	names []string
+}
+
+func (s *httpSource) Name() string {
+	return s.baseURL
+}
+
+func (s *httpSource) List() ([]string, error) {
+	if s.names != nil {
+		return s.names, nil
+	}
+	content, err := s.Read(IndexFileName)
+	if errors.Is(err, errFileNotFound) {
+		if Embedded == nil {
+			return nil, fmt.Errorf("%s has no %s and no prompt files were embedded in this build to look up", s.baseURL, IndexFileName)
+		}
+		logger.Debug("%s has no %s, looking up the embedded prompt files", s.baseURL, IndexFileName)
+		return (&fsSource{files: Embedded}).List()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var index Index
+	err = json.Unmarshal(content, &index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s/%s: %w", s.baseURL, IndexFileName, err)
+	}
+	var names []string
+	for _, name := range index.Prompts {
+		if !isPromptFile(name) || strings.ContainsAny(name, `/\`) {
+			logger.Warning("Ignoring '%s' in %s/%s, it is not a prompt file name", name, s.baseURL, IndexFileName)
+			continue
+		}
+		names = append(names, name)
+	}
+	s.names = names
+	return names, nil
+}
+
+func (s *httpSource) Read(name string) ([]byte, error) {
+	fileURL := s.baseURL + "/" + name
+	resp, err := httpGet(fileURL)
+	if err != nil {
+		return nil, errs.Network("network error fetching %s: %w", fileURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w at %s (HTTP 404)", errFileNotFound, fileURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server error fetching %s: HTTP %d", fileURL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+var errFileNotFound = errors.New("file not found")
+
+// gitHubSource fetches the prompt files of the docli repository through the
+// GitHub contents API
+type gitHubSource struct{}
+
+// GitHub repository information
+const (
+	apiURL  = "https://api.github.com/repos/Hasankanso/docli/contents/.github/prompts"
+	repoAPI = "https://api.github.com/repos/Hasankanso/docli/contents/"
+)
+
+func (s *gitHubSource) Name() string {
+	return SourceGitHub
+}
+
+func (s *gitHubSource) List() ([]string, error) {
+	files, err := getPromptFilesFromGitHub(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		// Only process .prompt.md files
+		if isPromptFile(file.Name) {
+			names = append(names, file.Name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (s *gitHubSource) Read(name string) ([]byte, error) {
+	return fetchFileContentFromGitHub(".github/prompts/" + name)
+}
+
+func httpGet(url string) (*http.Response, error) {
 	// Create HTTP client with timeout
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
+	logger.Debug("Fetching %s", url)
+	return client.Get(url)
+}
 
+func getPromptFilesFromGitHub(apiURL string) ([]GitHubFile, error) {
 	// Make the HTTP request to GitHub API
-	resp, err := client.Get(apiURL)
+	resp, err := httpGet(apiURL)
 	if err != nil {
-		return nil, fmt.Errorf("network error fetching directory listing: %w", err)
+		return nil, errs.Network("network error fetching directory listing: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -97,32 +380,27 @@ func getPromptFilesFromGitHub(apiURL string) ([]GitHubFile, error) {
 		return nil, fmt.Errorf("failed to parse GitHub API response: %w", err)
 	}
 
+	logger.Debug("Found %d file(s) in the prompts directory", len(files))
 	return files, nil
 }
 
-func fetchFileContentFromGitHub(filePath, destPath string) error {
+func fetchFileContentFromGitHub(filePath string) ([]byte, error) {
 	// Construct GitHub API URL for file content
-	const repoAPI = "https://api.github.com/repos/Hasankanso/docli/contents/"
 	fileURL := repoAPI + filePath
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
 	// Make the HTTP request to GitHub API
-	resp, err := client.Get(fileURL)
+	resp, err := httpGet(fileURL)
 	if err != nil {
-		return fmt.Errorf("network error fetching %s: %w", filePath, err)
+		return nil, errs.Network("network error fetching %s: %w", filePath, err)
 	}
 	defer resp.Body.Close()
 
 	// Check if the response is successful
 	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("file not found at %s (HTTP 404)", filePath)
+		return nil, fmt.Errorf("file not found at %s (HTTP 404)", filePath)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("server error fetching %s: HTTP %d", filePath, resp.StatusCode)
+		return nil, fmt.Errorf("server error fetching %s: HTTP %d", filePath, resp.StatusCode)
 	}
 
 	// Parse the JSON response to get file content
@@ -130,20 +408,13 @@ func fetchFileContentFromGitHub(filePath, destPath string) error {
 	decoder := json.NewDecoder(resp.Body)
 	err = decoder.Decode(&fileContent)
 	if err != nil {
-		return fmt.Errorf("failed to parse GitHub API response for %s: %w", filePath, err)
+		return nil, fmt.Errorf("failed to parse GitHub API response for %s: %w", filePath, err)
 	}
 
 	// Decode base64 content
 	decodedContent, err := base64.StdEncoding.DecodeString(fileContent.Content)
 	if err != nil {
-		return fmt.Errorf("failed to decode base64 content for %s: %w", filePath, err)
+		return nil, fmt.Errorf("failed to decode base64 content for %s: %w", filePath, err)
 	}
-
-	// Write the decoded content to the destination file
-	err = os.WriteFile(destPath, decodedContent, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write file %s: %w", destPath, err)
-	}
-
-	return nil
+	return decodedContent, nil
 }
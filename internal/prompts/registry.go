@@ -0,0 +1,291 @@
+package prompts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+)
+
+// SourceDefault takes every prompt from the registries declared in spec.json
+// and the user config, and from the embedded prompts when no registry has it
+const SourceDefault = "default"
+
+// gitPrefix marks a git repository as a prompt source, e.g.
+// git+https://github.com/acme/prompts.git#v2
+const gitPrefix = "git+"
+
+// Registry is a named prompt source declared in spec.json or the user config
+type Registry struct {
+	Name   string "json:\"name\""
+	Source string "json:\"source\""
+}
+
+// Validate checks that a registry has a usable name and source
+func (r Registry) Validate() error {
+	switch {
+	case r.Name == "":
+		return fmt.Errorf("a prompt source needs a name")
+	case r.Name == SourceDefault || r.Name == SourceEmbedded || r.Name == SourceGitHub:
+		return fmt.Errorf("'%s' is reserved, pick another name for the prompt source", r.Name)
+	case strings.ContainsAny(r.Name, `/\`):
+		return fmt.Errorf("prompt source name '%s' must not contain slashes", r.Name)
+	case r.Source == "":
+		return fmt.Errorf("prompt source '%s' has no source", r.Name)
+	}
+	return nil
+}
+
+// RegistryName derives a registry name from a source, e.g. acme-prompts for
+// git+https://github.com/acme/acme-prompts.git
+func RegistryName(source string) string {
+	source = strings.TrimPrefix(source, gitPrefix)
+	source, _, _ = strings.Cut(source, "#")
+	name := path.Base(strings.TrimRight(filepath.ToSlash(source), "/"))
+	return strings.TrimSuffix(name, ".git")
+}
+
+// FindRegistry returns the registry with the given name
+func FindRegistry(registries []Registry, name string) (Registry, bool) {
+	for _, registry := range registries {
+		if registry.Name == name {
+			return registry, true
+		}
+	}
+	return Registry{}, false
+}
+
+// layeredSource combines sources by precedence: a prompt is taken from the
+// first source that has it
+type layeredSource struct {
+	name    string
+	sources []Source
+	// origins maps each prompt to the source that provides it, filled by List
+	origins map[string]Source
+}
+
+func (s *layeredSource) Name() string {
+	return s.name
+}
+
+func (s *layeredSource) List() ([]string, error) {
+	s.origins = map[string]Source{}
+	var names []string
+	for _, source := range s.sources {
+		provided, err := source.List()
+		if err != nil {
+			return nil, fmt.Errorf("prompt source '%s': %w", source.Name(), err)
+		}
+		for _, name := range provided {
+			if _, found := s.origins[name]; found {
+				logger.Debug("%s of '%s' is overridden by '%s'", name, source.Name(), s.origins[name].Name())
+				continue
+			}
+			s.origins[name] = source
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (s *layeredSource) Read(name string) ([]byte, error) {
+	if s.origins == nil {
+		_, err := s.List()
+		if err != nil {
+			return nil, err
+		}
+	}
+	source, found := s.origins[name]
+	if !found {
+		return nil, fmt.Errorf("no prompt source has %s", name)
+	}
+	return source.Read(name)
+}
+
+// Origin returns the name of the source a prompt is taken from
+func (s *layeredSource) Origin(name string) string {
+	if source, found := s.origins[name]; found {
+		return source.Name()
+	}
+	return ""
+}
+
+// registrySource is the source of a registry, named after it
+type registrySource struct {
+	Source
+	name string
+}
+
+func (s *registrySource) Name() string {
+	return s.name
+}
+
+// gitSource clones a git repository and reads the prompt files in its
+// .github/prompts directory, or at its top when it has none
+type gitSource struct {
+	repository string
+	ref        string
+	files      *fsSource
+}
+
+func newGitSource(source string) *gitSource {
+	repository, ref, _ := strings.Cut(strings.TrimPrefix(source, gitPrefix), "#")
+	return &gitSource{repository: repository, ref: ref}
+}
+
+func (s *gitSource) Name() string {
+	if s.ref != "" {
+		return gitPrefix + s.repository + "#" + s.ref
+	}
+	return gitPrefix + s.repository
+}
+
+func (s *gitSource) List() ([]string, error) {
+	err := s.clone()
+	if err != nil {
+		return nil, err
+	}
+	return s.files.List()
+}
+
+func (s *gitSource) Read(name string) ([]byte, error) {
+	err := s.clone()
+	if err != nil {
+		return nil, err
+	}
+	return s.files.Read(name)
+}
+
+// clone makes a fresh shallow clone in the user cache directory, once
+func (s *gitSource) clone() error {
+	if s.files != nil {
+		return nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(s.Name()))
+	dir := filepath.Join(cacheDir, "docli", "prompts", hex.EncodeToString(sum[:8]))
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return fmt.Errorf("failed to clear %s: %w", dir, err)
+	}
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if s.ref != "" {
+		args = append(args, "--branch", s.ref)
+	}
+	args = append(args, s.repository, dir)
+	logger.Debug("Running git %s", strings.Join(args, " "))
+	command := exec.Command("git", args...)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return errs.Network("failed to clone %s: %v: %s", s.repository, err, strings.TrimSpace(string(output)))
+	}
+
+	promptsDir := filepath.Join(dir, ".github", "prompts")
+	if info, err := os.Stat(promptsDir); err == nil && info.IsDir() {
+		dir = promptsDir
+	}
+	s.files = &fsSource{name: s.Name(), files: os.DirFS(dir)}
+	return nil
+}
+
+// Precedence orders the registries of the project and the user config, the
+// first registry that has a prompt provides it
+func Precedence(project, user []Registry) []Registry {
+	return slices.Concat(project, user)
+}
+
+type AddPromptsCommand struct {
+	Registry Registry
+	// Project and User are the registries declared so far in spec.json and
+	// the user config
+	Project []Registry
+	User    []Registry
+	// UserScope declares the registry in the user config instead of spec.json
+	UserScope bool
+	// Declare records the registry in spec.json or the user config
+	Declare func(registry Registry) error
+}
+
+func NewAddPromptsCommand(registry Registry, project, user []Registry, userScope bool, declare func(registry Registry) error) *AddPromptsCommand {
+	return &AddPromptsCommand{
+		Registry:  registry,
+		Project:   project,
+		User:      user,
+		UserScope: userScope,
+		Declare:   declare,
+	}
+}
+
+// Run declares a registry and installs the prompts it provides. Prompts a
+// registry with precedence also has are left alone, prompts installed from
+// a registry or the embedded prompts it overrides are updated to its
+// version.
+func (cmd *AddPromptsCommand) Run() error {
+	source, err := newRegistrySource(cmd.Registry)
+	if err != nil {
+		return err
+	}
+	declared, found := FindRegistry(Precedence(cmd.Project, cmd.User), cmd.Registry.Name)
+	if found && declared.Source != cmd.Registry.Source {
+		return errs.Validation("prompt source '%s' is already declared for %s", declared.Name, declared.Source)
+	}
+
+	names, err := source.List()
+	if err != nil {
+		return fmt.Errorf("failed to get the prompt files of '%s': %w", cmd.Registry.Name, err)
+	}
+	if len(names) == 0 {
+		return errs.Validation("no prompt files found in %s", cmd.Registry.Source)
+	}
+
+	project, user := cmd.Project, cmd.User
+	if !found {
+		err = cmd.Declare(cmd.Registry)
+		if err != nil {
+			return fmt.Errorf("failed to declare prompt source '%s': %w", cmd.Registry.Name, err)
+		}
+		logger.Info("Added prompt source '%s' (%s)", cmd.Registry.Name, cmd.Registry.Source)
+		if cmd.UserScope {
+			user = append(slices.Clone(user), cmd.Registry)
+		} else {
+			project = append(slices.Clone(project), cmd.Registry)
+		}
+	}
+
+	defaultSource, err := NewSource(SourceDefault, Precedence(project, user))
+	if err != nil {
+		return err
+	}
+	_, err = defaultSource.List()
+	if err != nil {
+		return err
+	}
+	layered := defaultSource.(*layeredSource)
+	var provided []string
+	for _, name := range names {
+		if origin := layered.Origin(name); origin != cmd.Registry.Name {
+			logger.Info("%s is taken from '%s', which takes precedence", name, origin)
+			continue
+		}
+		provided = append(provided, name)
+	}
+	if len(provided) == 0 {
+		logger.Success("Prompt source '%s' added, all of its prompts are overridden", cmd.Registry.Name)
+		return nil
+	}
+
+	update := NewUpdatePromptsCommand(defaultSource, provided)
+	return update.Run()
+}
@@ -0,0 +1,204 @@
+package prompts
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/output"
+)
+
+// Statuses of an installed prompt compared with its source
+const (
+	StatusCurrent = "current"
+	// StatusModified prompts were changed locally since they were installed
+	StatusModified = "modified"
+	// StatusOutdated prompts have a newer version in the source
+	StatusOutdated = "outdated"
+	// StatusDiverged prompts were changed both locally and in the source
+	StatusDiverged = "diverged"
+	StatusMissing  = "missing"
+	// StatusUntracked prompts differ from the source but are not in the lock
+	// file, so local changes cannot be told apart from upstream ones
+	StatusUntracked = "untracked"
+	// StatusCustom prompts exist only locally
+	StatusCustom = "custom"
+)
+
+// PromptState compares the local copy of a prompt with the installed version
+// and the version available in the source
+type PromptState struct {
+	Name   string "json:\"name\""
+	Status string "json:\"status\""
+	// Source is the registry the prompt is taken from
+	Source        string "json:\"source,omitempty\""
+	InstalledHash string "json:\"installed_hash,omitempty\""
+	LocalHash     string "json:\"local_hash,omitempty\""
+	AvailableHash string "json:\"available_hash,omitempty\""
+
+	local     []byte
+	available []byte
+}
+
+// Path returns the local path of the prompt
+func (s *PromptState) Path() string {
+	return filepath.Join(PromptsDir, s.Name)
+}
+
+func (s *PromptState) hasLocal() bool {
+	return s.LocalHash != ""
+}
+
+func (s *PromptState) hasAvailable() bool {
+	return s.AvailableHash != ""
+}
+
+// inspectPrompts compares the local prompts with the source, limited to names
+// when any are given
+func inspectPrompts(source Source, lock *Lock, names []string) ([]PromptState, error) {
+	available, err := source.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt files list: %w", err)
+	}
+	local, err := localPrompts()
+	if err != nil {
+		return nil, err
+	}
+
+	all := slices.Concat(available, local)
+	slices.Sort(all)
+	all = slices.Compact(all)
+	for _, name := range names {
+		if !slices.Contains(all, name) {
+			return nil, errs.NotFound("prompt '%s' not found locally or in %s", name, source.Name())
+		}
+	}
+
+	var states []PromptState
+	for _, name := range all {
+		if len(names) > 0 && !slices.Contains(names, name) {
+			continue
+		}
+		state := PromptState{Name: name, InstalledHash: lock.Prompts[name].Hash}
+		if slices.Contains(local, name) {
+			state.local, err = os.ReadFile(state.Path())
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", state.Path(), err)
+			}
+			state.LocalHash = contentHash(state.local)
+		}
+		if slices.Contains(available, name) {
+			state.available, err = source.Read(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+			}
+			state.AvailableHash = contentHash(state.available)
+		}
+		if origins, ok := source.(interface{ Origin(name string) string }); ok {
+			state.Source = origins.Origin(name)
+		} else if state.hasAvailable() {
+			state.Source = source.Name()
+		}
+		state.Status = promptStatus(&state)
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// Inspect compares every local prompt with the source
+func Inspect(source Source) ([]PromptState, error) {
+	lock, err := LoadLock()
+	if err != nil {
+		return nil, err
+	}
+	return inspectPrompts(source, lock, nil)
+}
+
+func promptStatus(state *PromptState) string {
+	switch {
+	case !state.hasAvailable():
+		return StatusCustom
+	case !state.hasLocal():
+		return StatusMissing
+	case bytes.Equal(state.local, state.available):
+		return StatusCurrent
+	case state.InstalledHash == "":
+		return StatusUntracked
+	}
+	modified := state.LocalHash != state.InstalledHash
+	outdated := state.AvailableHash != state.InstalledHash
+	switch {
+	case modified && outdated:
+		return StatusDiverged
+	case modified:
+		return StatusModified
+	case outdated:
+		return StatusOutdated
+	}
+	return StatusCurrent
+}
+
+// localPrompts lists the prompt files in .github/prompts
+func localPrompts() ([]string, error) {
+	entries, err := os.ReadDir(PromptsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", PromptsDir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isPromptFile(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// shortHash abbreviates a hash for the table output
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+type ListPromptsCommand struct {
+	Source Source
+	Output string
+}
+
+func NewListPromptsCommand(source Source, output string) *ListPromptsCommand {
+	return &ListPromptsCommand{
+		Source: source,
+		Output: output,
+	}
+}
+
+func (cmd *ListPromptsCommand) Run() error {
+	lock, err := LoadLock()
+	if err != nil {
+		return err
+	}
+	states, err := inspectPrompts(cmd.Source, lock, nil)
+	if err != nil {
+		return err
+	}
+	if states == nil {
+		states = []PromptState{}
+	}
+
+	table := &output.Table{Columns: []string{"name", "status", "source", "installed", "local", "available"}}
+	for _, state := range states {
+		table.AddRow(state.Name, state.Status, state.Source, shortHash(state.InstalledHash), shortHash(state.LocalHash), shortHash(state.AvailableHash))
+	}
+	err = output.Write(os.Stdout, cmd.Output, table, states)
+	if err != nil {
+		return fmt.Errorf("error listing prompts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package prompts
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/diff"
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+)
+
+type DiffPromptsCommand struct {
+	Source Source
+	// Names limits the diff to these prompts, all prompts when empty
+	Names []string
+}
+
+func NewDiffPromptsCommand(source Source, names []string) *DiffPromptsCommand {
+	return &DiffPromptsCommand{
+		Source: source,
+		Names:  names,
+	}
+}
+
+func (cmd *DiffPromptsCommand) Run() error {
+	lock, err := LoadLock()
+	if err != nil {
+		return err
+	}
+	states, err := inspectPrompts(cmd.Source, lock, cmd.Names)
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, state := range states {
+		if !state.hasAvailable() {
+			continue
+		}
+		changes := diff.Unified(state.Path()+" (local)", state.Name+" ("+cmd.Source.Name()+")", string(state.local), string(state.available), 3)
+		if changes != "" {
+			fmt.Print(changes)
+			changed++
+		}
+	}
+	if changed == 0 {
+		logger.Info("Local prompts match %s", cmd.Source.Name())
+	}
+	return nil
+}
+
+// Results of updating a prompt
+const (
+	promptInstalled = "installed"
+	promptUpdated   = "updated"
+	promptMerged    = "merged"
+	promptKept      = "kept"
+	promptConflict  = "conflict"
+	promptSkipped   = "skipped"
+	promptUnchanged = "unchanged"
+	promptReset     = "reset"
+)
+
+var promptResults = []string{promptInstalled, promptUpdated, promptMerged, promptReset, promptKept, promptUnchanged, promptSkipped, promptConflict}
+
+type UpdatePromptsCommand struct {
+	Source Source
+	// Names limits the update to these prompts, all prompts when empty
+	Names []string
+}
+
+func NewUpdatePromptsCommand(source Source, names []string) *UpdatePromptsCommand {
+	return &UpdatePromptsCommand{
+		Source: source,
+		Names:  names,
+	}
+}
+
+// Run brings the prompts up to date with the source. Prompts without local
+// changes are replaced, locally modified prompts are merged with the new
+// version using the installed version as the base, and prompts whose merge
+// conflicts are left alone.
+func (cmd *UpdatePromptsCommand) Run() error {
+	lock, err := LoadLock()
+	if err != nil {
+		return err
+	}
+	states, err := inspectPrompts(cmd.Source, lock, cmd.Names)
+	if err != nil {
+		return err
+	}
+	lock.Source = cmd.Source.Name()
+
+	counts := map[string]int{}
+	for _, state := range states {
+		if state.Status == StatusCustom {
+			continue
+		}
+		result, detail, err := cmd.update(lock, &state)
+		if err != nil {
+			return err
+		}
+		counts[result]++
+		switch result {
+		case promptConflict, promptSkipped:
+			logger.Warning("%-10s %s: %s", result, state.Name, detail)
+		case promptUnchanged:
+			logger.Debug("%-10s %s", result, state.Name)
+		default:
+			logger.Info("%-10s %s", result, state.Name)
+		}
+	}
+
+	err = lock.Save()
+	if err != nil {
+		return err
+	}
+	summary := promptSummary(counts)
+	if counts[promptConflict] > 0 {
+		logger.Info("See the changes with 'docli prompts diff' and discard your own with 'docli prompts reset <name>'")
+		return errs.Conflict("refused to update %d prompt(s) with conflicting changes: %s", counts[promptConflict], summary)
+	}
+	logger.Success("Prompts updated from %s: %s", cmd.Source.Name(), summary)
+	return nil
+}
+
+func (cmd *UpdatePromptsCommand) update(lock *Lock, state *PromptState) (string, string, error) {
+	switch state.Status {
+	case StatusMissing:
+		return promptInstalled, "", install(lock, state, state.available)
+	case StatusOutdated:
+		return promptUpdated, "", install(lock, state, state.available)
+	case StatusModified:
+		return promptKept, "", nil
+	case StatusUntracked:
+		return promptSkipped, "it differs from the source and was not installed by docli, nothing tells local changes apart", nil
+	case StatusCurrent:
+		if state.InstalledHash != state.AvailableHash {
+			// Track the prompt, or catch up with an identical upstream change
+			return promptUnchanged, "", lock.Record(state.Name, state.available)
+		}
+		return promptUnchanged, "", nil
+	}
+
+	// Diverged, merge the upstream changes into the local ones
+	base, found, err := lock.Base(state.Name)
+	if err != nil {
+		return "", "", err
+	}
+	if !found {
+		return promptSkipped, "the installed version is unknown, so the changes cannot be merged", nil
+	}
+	merged, conflicts := diff.Merge3(string(base), string(state.local), string(state.available), diff.MergeLabels{Ours: "local", Theirs: cmd.Source.Name()})
+	if conflicts > 0 {
+		return promptConflict, fmt.Sprintf("the merge has %d conflicting section(s), local file kept", conflicts), nil
+	}
+	err = os.WriteFile(state.Path(), []byte(merged), 0644)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", state.Path(), err)
+	}
+	return promptMerged, "", lock.Record(state.Name, state.available)
+}
+
+type ResetPromptsCommand struct {
+	Source Source
+	// Names limits the reset to these prompts, all prompts when empty
+	Names []string
+	// Confirm is asked before local changes are discarded; a nil Confirm
+	// discards without asking
+	Confirm func(names []string) bool
+}
+
+func NewResetPromptsCommand(source Source, names []string, confirm func(names []string) bool) *ResetPromptsCommand {
+	return &ResetPromptsCommand{
+		Source:  source,
+		Names:   names,
+		Confirm: confirm,
+	}
+}
+
+// Run replaces the prompts with the version in the source, discarding local
+// changes
+func (cmd *ResetPromptsCommand) Run() error {
+	lock, err := LoadLock()
+	if err != nil {
+		return err
+	}
+	states, err := inspectPrompts(cmd.Source, lock, cmd.Names)
+	if err != nil {
+		return err
+	}
+	lock.Source = cmd.Source.Name()
+
+	var discarded []string
+	for _, state := range states {
+		if state.hasLocal() && state.hasAvailable() && !bytes.Equal(state.local, state.available) && state.Status != StatusOutdated {
+			discarded = append(discarded, state.Name)
+		}
+	}
+	if len(discarded) > 0 && cmd.Confirm != nil && !cmd.Confirm(discarded) {
+		logger.Info("Reset cancelled")
+		return nil
+	}
+
+	counts := map[string]int{}
+	for _, state := range states {
+		if state.Status == StatusCustom {
+			continue
+		}
+		result := promptUnchanged
+		if state.Status != StatusCurrent || state.InstalledHash != state.AvailableHash {
+			err := install(lock, &state, state.available)
+			if err != nil {
+				return err
+			}
+			result = promptReset
+			logger.Info("%-10s %s", result, state.Name)
+		}
+		counts[result]++
+	}
+
+	err = lock.Save()
+	if err != nil {
+		return err
+	}
+	logger.Success("Prompts reset to %s: %s", cmd.Source.Name(), promptSummary(counts))
+	return nil
+}
+
+// install writes content to the local prompt and records it as installed
+func install(lock *Lock, state *PromptState, content []byte) error {
+	err := os.MkdirAll(PromptsDir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", PromptsDir, err)
+	}
+	err = os.WriteFile(state.Path(), content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", state.Path(), err)
+	}
+	return lock.Record(state.Name, content)
+}
+
+func promptSummary(counts map[string]int) string {
+	var parts []string
+	for _, result := range promptResults {
+		if counts[result] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[result], result))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing to do"
+	}
+	return strings.Join(parts, ", ")
+}
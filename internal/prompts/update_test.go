@@ -0,0 +1,265 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Hasankanso/docli/internal/errs"
+)
+
+// memorySource is a prompt source with its prompts in memory
+type memorySource map[string]string
+
+func (s memorySource) Name() string {
+	return "test"
+}
+
+func (s memorySource) List() ([]string, error) {
+	var names []string
+	for name := range s {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (s memorySource) Read(name string) ([]byte, error) {
+	content, found := s[name]
+	if !found {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+const promptV1 = "# Sync\n\nRead the spec.\n\nUpdate the document.\n\nKeep it short.\n"
+
+// newPromptsProject installs the prompts of source into a temporary working
+// directory
+func newPromptsProject(t *testing.T, source memorySource) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	err := NewUpdatePromptsCommand(source, nil).Run()
+	if err != nil {
+		t.Fatalf("installing the prompts: %v", err)
+	}
+}
+
+func promptStatuses(t *testing.T, source Source) map[string]string {
+	t.Helper()
+	states, err := Inspect(source)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	statuses := map[string]string{}
+	for _, state := range states {
+		statuses[state.Name] = state.Status
+	}
+	return statuses
+}
+
+func writePrompt(t *testing.T, name, content string) {
+	t.Helper()
+	err := os.WriteFile(filepath.Join(PromptsDir, name), []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func readPrompt(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(PromptsDir, name))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return string(content)
+}
+
+func loadLock(t *testing.T) *Lock {
+	t.Helper()
+	lock, err := LoadLock()
+	if err != nil {
+		t.Fatalf("LoadLock: %v", err)
+	}
+	return lock
+}
+
+func TestPromptStatuses(t *testing.T) {
+	source := memorySource{
+		"current.prompt.md":  promptV1,
+		"modified.prompt.md": promptV1,
+		"outdated.prompt.md": promptV1,
+		"diverged.prompt.md": promptV1,
+		"missing.prompt.md":  promptV1,
+	}
+	newPromptsProject(t, source)
+	lock := loadLock(t)
+	if lock.Source != "test" || len(lock.Prompts) != len(source) {
+		t.Fatalf("lock of source %q has %d prompt(s), want %d", lock.Source, len(lock.Prompts), len(source))
+	}
+	for name, entry := range lock.Prompts {
+		if entry.Hash != contentHash([]byte(promptV1)) {
+			t.Errorf("lock records hash %s for %s", entry.Hash, name)
+		}
+	}
+
+	writePrompt(t, "modified.prompt.md", promptV1+"\nMine.\n")
+	source["outdated.prompt.md"] = promptV1 + "\nUpstream.\n"
+	writePrompt(t, "diverged.prompt.md", "# Mine\n"+promptV1[len("# Sync\n"):])
+	source["diverged.prompt.md"] = promptV1 + "\nUpstream.\n"
+	os.Remove(filepath.Join(PromptsDir, "missing.prompt.md"))
+	// Not installed by docli and different from the source
+	source["untracked.prompt.md"] = promptV1
+	writePrompt(t, "untracked.prompt.md", "# Someone else's\n")
+	writePrompt(t, "custom.prompt.md", "# Only here\n")
+
+	want := map[string]string{
+		"current.prompt.md":   StatusCurrent,
+		"modified.prompt.md":  StatusModified,
+		"outdated.prompt.md":  StatusOutdated,
+		"diverged.prompt.md":  StatusDiverged,
+		"missing.prompt.md":   StatusMissing,
+		"untracked.prompt.md": StatusUntracked,
+		"custom.prompt.md":    StatusCustom,
+	}
+	got := promptStatuses(t, source)
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s is %s, want %s", name, got[name], status)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
+
+func TestUpdatePrompts(t *testing.T) {
+	source := memorySource{
+		"modified.prompt.md": promptV1,
+		"outdated.prompt.md": promptV1,
+		"merged.prompt.md":   promptV1,
+		"missing.prompt.md":  promptV1,
+	}
+	newPromptsProject(t, source)
+
+	modified := promptV1 + "\nMine.\n"
+	writePrompt(t, "modified.prompt.md", modified)
+	upstream := strings.Replace(promptV1, "Keep it short.", "Keep it short and precise.", 1)
+	source["outdated.prompt.md"] = upstream
+	writePrompt(t, "merged.prompt.md", strings.Replace(promptV1, "# Sync", "# Sync the docs", 1))
+	source["merged.prompt.md"] = upstream
+	os.Remove(filepath.Join(PromptsDir, "missing.prompt.md"))
+	source["untracked.prompt.md"] = promptV1
+	writePrompt(t, "untracked.prompt.md", "# Someone else's\n")
+
+	err := NewUpdatePromptsCommand(source, nil).Run()
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	lock := loadLock(t)
+	want := map[string]struct {
+		content string
+		hash    string
+	}{
+		// Local changes are kept and the installed version stays the base
+		"modified.prompt.md": {modified, contentHash([]byte(promptV1))},
+		"outdated.prompt.md": {upstream, contentHash([]byte(upstream))},
+		"merged.prompt.md":   {strings.Replace(upstream, "# Sync", "# Sync the docs", 1), contentHash([]byte(upstream))},
+		"missing.prompt.md":  {promptV1, contentHash([]byte(promptV1))},
+		// Left alone, nothing tells its local changes apart
+		"untracked.prompt.md": {"# Someone else's\n", ""},
+	}
+	for name, expected := range want {
+		if got := readPrompt(t, name); got != expected.content {
+			t.Errorf("%s is\n%s\nwant\n%s", name, got, expected.content)
+		}
+		if got := lock.Prompts[name].Hash; got != expected.hash {
+			t.Errorf("lock records %s for %s, want %s", got, name, expected.hash)
+		}
+	}
+	base, found, err := lock.Base("merged.prompt.md")
+	if err != nil || !found || string(base) != upstream {
+		t.Errorf("base of the merged prompt is %q (found %v, %v), want the upstream version", base, found, err)
+	}
+
+	got := promptStatuses(t, source)
+	for name, status := range map[string]string{
+		"modified.prompt.md":  StatusModified,
+		"outdated.prompt.md":  StatusCurrent,
+		"merged.prompt.md":    StatusModified,
+		"missing.prompt.md":   StatusCurrent,
+		"untracked.prompt.md": StatusUntracked,
+	} {
+		if got[name] != status {
+			t.Errorf("%s is %s after the update, want %s", name, got[name], status)
+		}
+	}
+}
+
+func TestUpdatePromptsConflict(t *testing.T) {
+	source := memorySource{"sync.prompt.md": promptV1}
+	newPromptsProject(t, source)
+	installed := loadLock(t).Prompts["sync.prompt.md"]
+
+	local := strings.Replace(promptV1, "Update the document.", "Rewrite the document.", 1)
+	writePrompt(t, "sync.prompt.md", local)
+	upstream := strings.Replace(promptV1, "Update the document.", "Patch the document.", 1)
+	source["sync.prompt.md"] = upstream
+
+	err := NewUpdatePromptsCommand(source, nil).Run()
+	if errs.KindOf(err) != errs.KindConflict {
+		t.Fatalf("update = %v (kind %v), want a conflict", err, errs.KindOf(err))
+	}
+	if got := readPrompt(t, "sync.prompt.md"); got != local {
+		t.Errorf("conflicting update changed the local prompt to\n%s", got)
+	}
+	lock := loadLock(t)
+	if got := lock.Prompts["sync.prompt.md"]; got.Hash != installed.Hash || !got.InstalledAt.Equal(installed.InstalledAt) {
+		t.Errorf("conflicting update changed the lock entry to %+v, want %+v", got, installed)
+	}
+	base, found, err := lock.Base("sync.prompt.md")
+	if err != nil || !found || string(base) != promptV1 {
+		t.Errorf("base after the conflict is %q (found %v, %v), want the installed version", base, found, err)
+	}
+	if got := promptStatuses(t, source)["sync.prompt.md"]; got != StatusDiverged {
+		t.Errorf("prompt is %s after the conflict, want %s", got, StatusDiverged)
+	}
+
+	// Resetting discards the local changes and installs the new version
+	err = NewResetPromptsCommand(source, []string{"sync.prompt.md"}, nil).Run()
+	if err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if got := readPrompt(t, "sync.prompt.md"); got != upstream {
+		t.Errorf("reset prompt is\n%s", got)
+	}
+	if got := loadLock(t).Prompts["sync.prompt.md"].Hash; got != contentHash([]byte(upstream)) {
+		t.Errorf("reset recorded hash %s", got)
+	}
+}
+
+func TestLockBaseMismatch(t *testing.T) {
+	source := memorySource{"sync.prompt.md": promptV1}
+	newPromptsProject(t, source)
+
+	// A base that does not match the lock is not used for merges
+	err := os.WriteFile(basePath("sync.prompt.md"), []byte("# Edited by hand\n"), 0644)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	_, found, err := loadLock(t).Base("sync.prompt.md")
+	if err != nil || found {
+		t.Errorf("Base of a mismatching file found %v, %v", found, err)
+	}
+	writePrompt(t, "sync.prompt.md", "# Mine\n"+promptV1[len("# Sync\n"):])
+	source["sync.prompt.md"] = promptV1 + "\nUpstream.\n"
+	err = NewUpdatePromptsCommand(source, nil).Run()
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := readPrompt(t, "sync.prompt.md"); strings.Contains(got, "Upstream.") {
+		t.Errorf("update merged without a base:\n%s", got)
+	}
+}
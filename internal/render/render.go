@@ -0,0 +1,336 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/hints"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/prompts"
+	"github.com/Hasankanso/docli/internal/spec"
+)
+
+// Context is the data a prompt template is executed with, e.g.
+// {{ .Name }} or {{ range .Files }}
+type Context struct {
+	ID          string            "json:\"id\""
+	Name        string            "json:\"name\""
+	Description string            "json:\"description,omitempty\""
+	Parent      string            "json:\"parent,omitempty\""
+	Labels      []string          "json:\"labels,omitempty\""
+	Properties  map[string]string "json:\"properties,omitempty\""
+	Platforms   []string          "json:\"platforms,omitempty\""
+	FileHints   []hints.Match     "json:\"file_hints\""
+	// Files lists every file the hints resolve to, Skipped the binary and
+	// oversized files they match
+	Files   []string        "json:\"files\""
+	Skipped []hints.Skipped "json:\"skipped,omitempty\""
+	// File is the generated markdown file of the document in .docs/ and
+	// Content its current content, empty when it was not generated yet
+	File    string "json:\"file\""
+	Content string "json:\"content,omitempty\""
+	// Changes are the git changes of the hinted files since the document was
+	// last synced, nil when it never was or git is unavailable
+	Changes *Changes "json:\"changes,omitempty\""
+}
+
+// Changes are the commits and the diff of the hinted files since a point in
+// time, uncommitted changes included
+type Changes struct {
+	Since   time.Time "json:\"since\""
+	Commits []string  "json:\"commits,omitempty\""
+	Diff    string    "json:\"diff,omitempty\""
+}
+
+// contextTemplate describes the document, it is appended to prompts that do
+// not use the context themselves and can be placed with
+// {{ template "context" . }}
+const contextTemplate = `{{ define "context" -}}
+## Document
+
+- ID: {{ .ID }}
+- Name: {{ .Name }}
+{{- if .Description }}
+- Description: {{ .Description }}
+{{- end }}
+{{- if .Platforms }}
+- Platforms: {{ join .Platforms ", " }}
+{{- end }}
+- File: {{ .File }}
+
+### File Hints
+{{ range .FileHints }}
+- {{ .Hint }}{{ if .Problem }} ({{ .Problem }}){{ else if not .Exists }} (matches nothing){{ else if .Exclude }} ({{ len .Files }} file(s) left out){{ else if or .IsDir .Glob }} ({{ len .Files }} file(s)){{ end }}
+{{- else }}
+No file hints.
+{{- end }}
+{{ if .Files }}
+### Files
+{{ range .Files }}
+- {{ . }}
+{{- end }}
+{{ end }}
+### Current Content
+{{ if .Content }}
+{{ fence .Content "markdown" }}
+{{ else }}
+The document was not generated yet.
+{{ end }}
+{{- with .Changes }}
+### Changes Since {{ .Since.UTC.Format "2006-01-02 15:04" }} UTC
+{{ range .Commits }}
+- {{ . }}
+{{- end }}
+{{- if .Diff }}
+
+{{ fence .Diff "diff" }}
+{{ else }}
+
+No changes to the hinted files.
+{{ end }}
+{{- end }}
+{{- end }}`
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"trim":  strings.TrimSpace,
+	"fence": hints.Fence,
+}
+
+// Render executes a prompt template with the context of a document. A prompt
+// that never uses the context gets it appended.
+func Render(name, prompt string, context *Context) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt %s: %w", name, err)
+	}
+	_, err = tmpl.New("context").Parse(contextTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	err = tmpl.ExecuteTemplate(&buffer, name, context)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", name, err)
+	}
+	if isStatic(tmpl) {
+		buffer.WriteString("\n\n")
+		err = tmpl.ExecuteTemplate(&buffer, "context", context)
+		if err != nil {
+			return "", fmt.Errorf("failed to render the context of %s: %w", name, err)
+		}
+	}
+	return strings.TrimRight(buffer.String(), "\n") + "\n", nil
+}
+
+// isStatic tells whether a template is plain text, like the prompts written
+// for IDE chats
+func isStatic(tmpl *template.Template) bool {
+	if tmpl.Tree == nil || tmpl.Tree.Root == nil {
+		return true
+	}
+	for _, node := range tmpl.Tree.Root.Nodes {
+		if node.Type() != parse.NodeText {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildContext gathers what a prompt needs to know about a document
+func BuildContext(specRepo *spec.SpecRepo, docSpec *spec.DocSpec, doc *spec.DocMetaData) (*Context, error) {
+	context := &Context{
+		ID:          doc.ID,
+		Name:        doc.Name,
+		Description: doc.Description,
+		Parent:      doc.Parent,
+		Labels:      doc.Labels,
+		Properties:  doc.Properties,
+		Platforms:   docSpec.Platforms,
+		File:        specRepo.DocFilePath(doc),
+	}
+
+	fileSet, err := hints.Resolve(doc.FileHints)
+	if err != nil {
+		return nil, err
+	}
+	context.FileHints = fileSet.Hints
+	context.Files = fileSet.Files
+	context.Skipped = fileSet.Skipped
+
+	content, err := os.ReadFile(context.File)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", context.File, err)
+	}
+	context.Content = string(content)
+
+	if doc.Targets != nil && doc.Targets.Confluence != nil && !doc.Targets.Confluence.LastSyncedAt.IsZero() {
+		context.Changes = gitChanges(doc.Targets.Confluence.LastSyncedAt, pathspecs(fileSet.Hints))
+	}
+	return context, nil
+}
+
+// pathspecs converts file hints to git pathspecs, invalid hints are left out
+func pathspecs(matches []hints.Match) []string {
+	var specs []string
+	for _, match := range matches {
+		if match.Problem != "" {
+			continue
+		}
+		pattern := strings.TrimPrefix(hints.Normalize(match.Hint), "!")
+		switch {
+		case match.Exclude && match.Glob:
+			specs = append(specs, ":(exclude,glob)"+pattern)
+		case match.Exclude:
+			specs = append(specs, ":(exclude)"+pattern)
+		case match.Glob:
+			specs = append(specs, ":(glob)"+pattern)
+		default:
+			specs = append(specs, pattern)
+		}
+	}
+	return specs
+}
+
+// gitChanges collects the commits and the diff of paths since a point in
+// time. Git failures are not fatal, the prompt is rendered without changes.
+func gitChanges(since time.Time, paths []string) *Changes {
+	if len(paths) == 0 {
+		return nil
+	}
+	base, err := git("rev-list", "-1", "--before="+since.UTC().Format(time.RFC3339), "HEAD")
+	if err != nil {
+		logger.Debug("Leaving out git changes: %v", err)
+		return nil
+	}
+	base = strings.TrimSpace(base)
+	if base == "" {
+		logger.Debug("Leaving out git changes: no commit before %s", since.UTC().Format(time.RFC3339))
+		return nil
+	}
+
+	changes := &Changes{Since: since}
+	log, err := git(slices.Concat([]string{"log", "--oneline", "--no-decorate", base + "..HEAD", "--"}, paths)...)
+	if err != nil {
+		logger.Debug("Leaving out git changes: %v", err)
+		return nil
+	}
+	for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+		if line != "" {
+			changes.Commits = append(changes.Commits, line)
+		}
+	}
+	changes.Diff, err = git(slices.Concat([]string{"diff", base, "--"}, paths)...)
+	if err != nil {
+		logger.Debug("Leaving out git changes: %v", err)
+		return nil
+	}
+	return changes
+}
+
+func git(args ...string) (string, error) {
+	logger.Debug("Running git %s", strings.Join(args, " "))
+	var stderr bytes.Buffer
+	command := exec.Command("git", args...)
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+type RenderPromptCommand struct {
+	SpecRepo *spec.SpecRepo
+	// Prompt is a prompt name like updateDoc, or the path of a prompt file
+	Prompt string
+	ID     string
+	// Source provides the prompts that are not in .github/prompts
+	Source prompts.Source
+}
+
+func NewRenderPromptCommand(specRepo *spec.SpecRepo, prompt, id string, source prompts.Source) *RenderPromptCommand {
+	return &RenderPromptCommand{
+		SpecRepo: specRepo,
+		Prompt:   prompt,
+		ID:       id,
+		Source:   source,
+	}
+}
+
+func (cmd *RenderPromptCommand) Run() error {
+	if !cmd.SpecRepo.SpecExists() {
+		return errs.SpecNotFound()
+	}
+	docSpec, err := cmd.SpecRepo.GetSpec()
+	if err != nil {
+		return fmt.Errorf("error reading documentation configuration: %w", err)
+	}
+	doc, err := cmd.SpecRepo.GetDocMeta(cmd.ID)
+	if err != nil {
+		return err
+	}
+
+	name, prompt, err := ReadPrompt(cmd.Prompt, cmd.Source)
+	if err != nil {
+		return err
+	}
+	context, err := BuildContext(cmd.SpecRepo, docSpec, doc)
+	if err != nil {
+		return err
+	}
+	rendered, err := Render(name, prompt, context)
+	if err != nil {
+		return errs.Validation("%v", err)
+	}
+	fmt.Print(rendered)
+	return nil
+}
+
+// ReadPrompt reads a prompt file, or a prompt by name from .github/prompts
+// and then from source
+func ReadPrompt(prompt string, source prompts.Source) (string, string, error) {
+	if info, err := os.Stat(prompt); err == nil && !info.IsDir() {
+		content, err := os.ReadFile(prompt)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read %s: %w", prompt, err)
+		}
+		return filepath.Base(prompt), string(content), nil
+	}
+
+	name := prompt
+	if !strings.HasSuffix(name, prompts.PromptSuffix) {
+		name += prompts.PromptSuffix
+	}
+	content, err := os.ReadFile(filepath.Join(prompts.PromptsDir, name))
+	if err == nil {
+		return name, string(content), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	available, err := source.List()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get prompt files list: %w", err)
+	}
+	if !slices.Contains(available, name) {
+		return "", "", errs.NotFound("prompt '%s' not found in %s or %s", prompt, prompts.PromptsDir, source.Name())
+	}
+	content, err = source.Read(name)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+	logger.Debug("Using %s from %s, it is not installed", name, source.Name())
+	return name, string(content), nil
+}
@@ -0,0 +1,51 @@
+package spec
+
+import (
+	"os"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/hints"
+)
+
+// Freshness compares the generated file of a document with its sources
+type Freshness struct {
+	Generated      bool      "json:\"generated\""
+	GeneratedAt    time.Time "json:\"generated_at,omitzero\""
+	NewestSource   string    "json:\"newest_source,omitempty\""
+	NewestSourceAt time.Time "json:\"newest_source_at,omitzero\""
+	// Stale is set when the document was never generated or a source
+	// changed after it was
+	Stale bool "json:\"stale\""
+}
+
+// CheckFreshness finds the most recently modified file the file hints of a
+// document resolve to and compares it with the generated file
+func (r *SpecRepo) CheckFreshness(doc *DocMetaData) (Freshness, error) {
+	var freshness Freshness
+	info, err := os.Stat(r.DocFilePath(doc))
+	if err != nil && !os.IsNotExist(err) {
+		return freshness, err
+	}
+	if err == nil {
+		freshness.Generated = true
+		freshness.GeneratedAt = info.ModTime()
+	}
+
+	fileSet, err := hints.Resolve(doc.FileHints)
+	if err != nil {
+		return freshness, err
+	}
+	for _, file := range fileSet.Files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(freshness.NewestSourceAt) {
+			freshness.NewestSource = file
+			freshness.NewestSourceAt = info.ModTime()
+		}
+	}
+
+	freshness.Stale = !freshness.Generated || freshness.NewestSourceAt.After(freshness.GeneratedAt)
+	return freshness, nil
+}
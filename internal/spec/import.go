@@ -0,0 +1,176 @@
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/lucsky/cuid"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseDocMeta reads one document or a list of documents given as JSON or
+// YAML, using the field names of spec.json. Documents without an ID get a new
+// one.
+func ParseDocMeta(content []byte) ([]DocMetaData, error) {
+	// YAML is a superset of JSON, decode generically and go through JSON so
+	// that the json field names apply to both
+	var root yaml.Node
+	err := yaml.Unmarshal(content, &root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON or YAML: %w", err)
+	}
+	if len(root.Content) > 0 {
+		documents := []*yaml.Node{root.Content[0]}
+		if root.Content[0].Kind == yaml.SequenceNode {
+			documents = root.Content[0].Content
+		}
+		for i, document := range documents {
+			err = prepareImport(document)
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %w", i+1, err)
+			}
+		}
+	}
+	var value interface{}
+	err = root.Decode(&value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON or YAML: %w", err)
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document metadata: %w", err)
+	}
+
+	var docs []DocMetaData
+	switch value.(type) {
+	case []interface{}:
+		err = json.Unmarshal(normalized, &docs)
+	case map[string]interface{}:
+		var doc DocMetaData
+		err = json.Unmarshal(normalized, &doc)
+		docs = append(docs, doc)
+	default:
+		return nil, fmt.Errorf("expected a document or a list of documents")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid document metadata: %w", err)
+	}
+
+	for i := range docs {
+		if docs[i].ID == "" {
+			docs[i].ID = cuid.Slug()
+		}
+		err := docs[i].Validate()
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+	}
+	return docs, nil
+}
+
+// prepareImport adjusts a document before it is decoded. Property values
+// like 1.2 or true are kept as the text they were written with, and the sync
+// state in targets is dropped so that it cannot replace the page mappings.
+func prepareImport(document *yaml.Node) error {
+	if document.Kind != yaml.MappingNode {
+		return nil
+	}
+	var fields []*yaml.Node
+	for i := 0; i+1 < len(document.Content); i += 2 {
+		key, value := document.Content[i], document.Content[i+1]
+		switch key.Value {
+		case "targets":
+			logger.Warning("Ignoring the targets of an imported document, they are set by syncing")
+			continue
+		case "properties":
+			if value.Kind != yaml.MappingNode {
+				break
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				property := value.Content[j+1]
+				if property.Kind != yaml.ScalarNode {
+					return fmt.Errorf("property '%s' must be a single value, not a list or a map", value.Content[j].Value)
+				}
+				property.Tag = "!!str"
+			}
+		}
+		fields = append(fields, key, value)
+	}
+	document.Content = fields
+	return nil
+}
+
+// Validate checks the fields of a document that can be set by hand
+func (d *DocMetaData) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("a name is required")
+	}
+	if d.Parent != "" && d.Parent == d.ID {
+		return fmt.Errorf("a document cannot be its own parent")
+	}
+	for _, label := range d.Labels {
+		if label != NormalizeLabel(label) {
+			return fmt.Errorf("invalid label '%s', labels are lower case without spaces", label)
+		}
+	}
+	for key := range d.Properties {
+		if key == "" {
+			return fmt.Errorf("property keys cannot be empty")
+		}
+	}
+	return nil
+}
+
+// editableDocMeta holds the fields of a document that are edited by hand, in
+// the order they are shown
+type editableDocMeta struct {
+	Name        string            "yaml:\"name\""
+	Description string            "yaml:\"description,omitempty\""
+	FileHints   []string          "yaml:\"file_hints,omitempty\""
+	Parent      string            "yaml:\"parent,omitempty\""
+	Labels      []string          "yaml:\"labels,omitempty\""
+	Properties  map[string]string "yaml:\"properties,omitempty\""
+}
+
+// EditableYAML renders the fields of a document that can be edited by hand
+// as YAML. The ID and the sync state are left out.
+func (d *DocMetaData) EditableYAML() ([]byte, error) {
+	return yaml.Marshal(editableDocMeta{
+		Name:        d.Name,
+		Description: d.Description,
+		FileHints:   d.FileHints,
+		Parent:      d.Parent,
+		Labels:      d.Labels,
+		Properties:  d.Properties,
+	})
+}
+
+// ApplyEditableYAML replaces the editable fields of a document with the ones
+// in content, as written by EditableYAML
+func (d *DocMetaData) ApplyEditableYAML(content []byte) error {
+	var edited editableDocMeta
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err := decoder.Decode(&edited)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	updated := *d
+	updated.Name = strings.TrimSpace(edited.Name)
+	updated.Description = edited.Description
+	updated.FileHints = edited.FileHints
+	updated.Parent = edited.Parent
+	updated.Labels = edited.Labels
+	updated.Properties = edited.Properties
+	err = updated.Validate()
+	if err != nil {
+		return err
+	}
+	*d = updated
+	return nil
+}
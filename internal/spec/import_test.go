@@ -0,0 +1,50 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDocMetaProperties(t *testing.T) {
+	docs, err := ParseDocMeta([]byte(`
+- id: api
+  name: API
+  properties:
+    version: 1.10
+    public: true
+    owner: platform
+    reviewed: 2026-10-18
+- {"id": "guide", "name": "Guide", "properties": {"version": 2}}
+`))
+	if err != nil {
+		t.Fatalf("ParseDocMeta: %v", err)
+	}
+	want := map[string]string{"version": "1.10", "public": "true", "owner": "platform", "reviewed": "2026-10-18"}
+	for key, value := range want {
+		if docs[0].Properties[key] != value {
+			t.Errorf("property %s = %q, want %q", key, docs[0].Properties[key], value)
+		}
+	}
+	if docs[1].Properties["version"] != "2" {
+		t.Errorf("JSON property version = %q, want 2", docs[1].Properties["version"])
+	}
+
+	_, err = ParseDocMeta([]byte("name: API\nproperties:\n  owners: [a, b]\n"))
+	if err == nil || !strings.Contains(err.Error(), "owners") {
+		t.Errorf("list property = %v, want an error naming the key", err)
+	}
+}
+
+func TestParseDocMetaDropsTargets(t *testing.T) {
+	docs, err := ParseDocMeta([]byte(`{
+		"id": "api",
+		"name": "API",
+		"targets": {"confluence": {"space_key": "DOC", "page_id": "1001"}}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseDocMeta: %v", err)
+	}
+	if len(docs) != 1 || docs[0].Name != "API" || docs[0].Targets != nil {
+		t.Errorf("ParseDocMeta = %+v, want API without targets", docs)
+	}
+}
@@ -0,0 +1,69 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NormalizeLabel turns a label into the form Confluence stores it in, lower
+// case with dashes instead of whitespace
+func NormalizeLabel(label string) string {
+	fields := strings.FieldsFunc(strings.ToLower(label), unicode.IsSpace)
+	return strings.Join(fields, "-")
+}
+
+// ParseProperty splits a "key=value" property
+func ParseProperty(property string) (string, string, error) {
+	key, value, found := strings.Cut(property, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return "", "", fmt.Errorf("invalid property '%s', expected key=value", property)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// PropertyKeys returns the keys of the document's properties in alphabetical
+// order
+func (d *DocMetaData) PropertyKeys() []string {
+	keys := make([]string, 0, len(d.Properties))
+	for key := range d.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AddLabels adds labels the document does not have yet
+func (d *DocMetaData) AddLabels(labels ...string) {
+	for _, label := range labels {
+		label = NormalizeLabel(label)
+		if label != "" && !d.HasLabel(label) {
+			d.Labels = append(d.Labels, label)
+		}
+	}
+}
+
+// RemoveLabels removes labels from the document
+func (d *DocMetaData) RemoveLabels(labels ...string) {
+	for _, label := range labels {
+		label = NormalizeLabel(label)
+		for i := range d.Labels {
+			if d.Labels[i] == label {
+				d.Labels = append(d.Labels[:i], d.Labels[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// HasLabel reports whether the document has a label
+func (d *DocMetaData) HasLabel(label string) bool {
+	for _, existing := range d.Labels {
+		if existing == label {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package spec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Hasankanso/docli/internal/logger"
+)
+
+// StateDir is where docli keeps its own bookkeeping next to spec.json
+func (r *SpecRepo) StateDir() string {
+	return filepath.Join(filepath.Dir(r.SpecJsonFilePath), ".docli")
+}
+
+func (r *SpecRepo) snapshotPath(kind, name string) string {
+	return filepath.Join(r.StateDir(), kind, name+".snapshot")
+}
+
+// ReadSnapshot returns the content last recorded for name, e.g. the markdown
+// of a document as of its last sync. The boolean is false when no snapshot
+// has been recorded yet.
+func (r *SpecRepo) ReadSnapshot(kind, name string) (string, bool, error) {
+	content, err := os.ReadFile(r.snapshotPath(kind, name))
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s snapshot of %s: %w", kind, name, err)
+	}
+	return string(content), true, nil
+}
+
+// WriteSnapshot records content as the latest known state of name
+func (r *SpecRepo) WriteSnapshot(kind, name, content string) error {
+	path := r.snapshotPath(kind, name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	logger.Debug("Recording %s snapshot of %s in %s", kind, name, path)
+	err = os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s snapshot of %s: %w", kind, name, err)
+	}
+	return nil
+}
+
+// RemoveSnapshot deletes the recorded state of name, if any
+func (r *SpecRepo) RemoveSnapshot(kind, name string) error {
+	err := os.Remove(r.snapshotPath(kind, name))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s snapshot of %s: %w", kind, name, err)
+	}
+	return nil
+}
+
+// ContentHash returns the hex encoded SHA-256 of content
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,9 @@
 package spec
 
 import (
+	"fmt"
+
+	"github.com/Hasankanso/docli/internal/errs"
 	"github.com/Hasankanso/docli/internal/logger"
 	"github.com/Hasankanso/docli/internal/prompts"
 )
@@ -8,37 +11,39 @@ import (
 type InitSpecCommand struct {
 	SpecRepo  *SpecRepo
 	Platforms []string
+	// PromptsSource is where the prompt files are copied from
+	PromptsSource prompts.Source
 }
 
-func NewInitSpecCommand(NewSpecRepo *SpecRepo, platforms []string) *InitSpecCommand {
+func NewInitSpecCommand(NewSpecRepo *SpecRepo, platforms []string, promptsSource prompts.Source) *InitSpecCommand {
 	return &InitSpecCommand{
-		SpecRepo:  NewSpecRepo,
-		Platforms: platforms,
+		SpecRepo:      NewSpecRepo,
+		Platforms:     platforms,
+		PromptsSource: promptsSource,
 	}
 }
 
-func (cmd *InitSpecCommand) Run() {
+func (cmd *InitSpecCommand) Run() error {
 
 	// Check if spec already exists
 	if cmd.SpecRepo.SpecExists() {
-		logger.Error("A spec file already exists at %s", cmd.SpecRepo.SpecFilePath)
-		logger.Info("If you want to re-initialize, please back up your existing %s and delete it"+
-			" before running 'docli init' again.", cmd.SpecRepo.SpecFilePath)
-		return
+		return errs.Validation("a spec file already exists at %s; if you want to re-initialize, please back up your existing %s "+
+			"and delete it before running 'docli init' again", cmd.SpecRepo.SpecFilePath, cmd.SpecRepo.SpecFilePath)
 	}
 
 	// Step 1: Copy prompt files
-	logger.Info("Copying needed prompt files...")
-	err := prompts.CopyPromptFiles()
+	logger.Info("Copying needed prompt files (source: %s)...", cmd.PromptsSource.Name())
+	err := prompts.CopyPromptFiles(cmd.PromptsSource)
 	if err != nil {
-		logger.Fatal("Failed to copy prompt files: %v", err)
+		return fmt.Errorf("failed to copy prompt files: %w", err)
 	}
 
 	// Step 2: Initialize spec repository
 	logger.Info("Initializing documentation configuration...")
 	err = cmd.SpecRepo.InitSpec(cmd.Platforms)
 	if err != nil {
-		logger.Fatal("Failed to initialize documentation configuration: %v", err)
+		return fmt.Errorf("failed to initialize documentation configuration: %w", err)
 	}
 	logger.Success("Documentation configuration initialized successfully")
+	return nil
 }
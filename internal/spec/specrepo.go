@@ -6,16 +6,24 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
+	"github.com/Hasankanso/docli/internal/errs"
+	"github.com/Hasankanso/docli/internal/logger"
+	"github.com/Hasankanso/docli/internal/prompts"
 	"github.com/lucsky/cuid"
 )
 
 // DocMetaData represents a single document configuration
 type DocMetaData struct {
-	ID          string   "json:\"id,omitempty\""
-	Name        string   "json:\"name\""
-	Description string   "json:\"description,omitempty\""
-	FileHints   []string "json:\"file_hints,omitempty\""
+	ID          string            "json:\"id,omitempty\""
+	Name        string            "json:\"name\""
+	Description string            "json:\"description,omitempty\""
+	FileHints   []string          "json:\"file_hints,omitempty\""
+	Parent      string            "json:\"parent,omitempty\""
+	Labels      []string          "json:\"labels,omitempty\""
+	Properties  map[string]string "json:\"properties,omitempty\""
+	Targets     *DocTargets       "json:\"targets,omitempty\""
 }
 
 func NewDocMetaData(name, description string, fileHints []string) *DocMetaData {
@@ -28,8 +36,12 @@ func NewDocMetaData(name, description string, fileHints []string) *DocMetaData {
 }
 
 type DocSpec struct {
-	Platforms []string      "json:\"platforms,omitempty\""
-	DocMeta   []DocMetaData "json:\"docmeta,omitempty\""
+	Platforms  []string        "json:\"platforms,omitempty\""
+	Confluence *ConfluenceSpec "json:\"confluence,omitempty\""
+	// PromptSources are the prompt registries of the project, they take
+	// precedence over the ones in the user config
+	PromptSources []prompts.Registry "json:\"prompt_sources,omitempty\""
+	DocMeta       []DocMetaData      "json:\"docmeta,omitempty\""
 }
 
 type SpecRepo struct {
@@ -72,6 +84,9 @@ func (r *SpecRepo) AddDocMeta(doc *DocMetaData) error {
 	if err != nil {
 		return err
 	}
+	if spec.FindDocMeta(doc.ID) != nil {
+		return fmt.Errorf("document's Meta data with ID '%s' already exists", doc.ID)
+	}
 
 	spec.DocMeta = append(spec.DocMeta, *doc)
 
@@ -91,7 +106,29 @@ func (r *SpecRepo) RemoveDocMeta(id string) error {
 		}
 	}
 
-	return fmt.Errorf("document's Meta data with ID '%s' not found", id)
+	return errs.NotFound("document's Meta data with ID '%s' not found", id)
+}
+
+// UpdateDocMeta changes the document with the given ID in place and saves the spec
+func (r *SpecRepo) UpdateDocMeta(id string, update func(doc *DocMetaData)) error {
+	spec, err := r.loadJsonSpec()
+	if err != nil {
+		return err
+	}
+	for i := range spec.DocMeta {
+		if spec.DocMeta[i].ID == id {
+			update(&spec.DocMeta[i])
+			if targets := spec.DocMeta[i].Targets; targets != nil && targets.Confluence == nil && targets.Readme == nil {
+				spec.DocMeta[i].Targets = nil
+			}
+			return r.Save(spec)
+		}
+	}
+	return errs.NotFound("document's Meta data with ID '%s' not found", id)
+}
+
+func (r *SpecRepo) GetSpec() (*DocSpec, error) {
+	return r.loadJsonSpec()
 }
 
 func (r *SpecRepo) GetAllDocMeta() ([]DocMetaData, error) {
@@ -102,6 +139,39 @@ func (r *SpecRepo) GetAllDocMeta() ([]DocMetaData, error) {
 	return spec.DocMeta, nil
 }
 
+// DocFilePath returns the path of the generated markdown file for a document,
+// e.g. "How to Use Docli" -> ".docs/how_to_use_docli.md"
+func (r *SpecRepo) DocFilePath(doc *DocMetaData) string {
+	return filepath.Join(filepath.Dir(r.SpecJsonFilePath), doc.FileName())
+}
+
+// ProjectDir is the directory holding the docs directory, which file hints
+// and the files referenced by documents are confined to
+func (r *SpecRepo) ProjectDir() string {
+	return filepath.Dir(filepath.Dir(r.SpecJsonFilePath))
+}
+
+// FileName converts the document name to its markdown file name
+func (d *DocMetaData) FileName() string {
+	var builder strings.Builder
+	pendingSeparator := false
+	for _, char := range strings.ToLower(d.Name) {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			if pendingSeparator && builder.Len() > 0 {
+				builder.WriteRune('_')
+			}
+			pendingSeparator = false
+			builder.WriteRune(char)
+		} else {
+			pendingSeparator = true
+		}
+	}
+	if builder.Len() == 0 {
+		return d.ID + ".md"
+	}
+	return builder.String() + ".md"
+}
+
 func (r *SpecRepo) AddPlatform(platform string) error {
 	spec, err := r.loadJsonSpec()
 	if err != nil {
@@ -130,23 +200,22 @@ func (r *SpecRepo) RemovePlatform(platform string) error {
 
 func (r *SpecRepo) Save(config *DocSpec) error {
 
-	err := r.saveJsonSpec(config)
+	docsDir := filepath.Dir(r.SpecJsonFilePath)
+	err := os.MkdirAll(docsDir, 0755)
 	if err != nil {
-		return fmt.Errorf("failed to save JSON spec: %w", err)
+		return fmt.Errorf("failed to create %s directory: %w", docsDir, err)
 	}
 
-	docsDir := ".docs"
-	err = os.MkdirAll(docsDir, 0755)
+	err = r.saveJsonSpec(config)
 	if err != nil {
-		return fmt.Errorf("failed to create .docs directory: %w", err)
+		return fmt.Errorf("failed to save JSON spec: %w", err)
 	}
 
 	// Generate spec.md content
 	content := generateSpecContent(config)
 
 	// Write to spec.md
-	specPath := filepath.Join(docsDir, "spec.md")
-	err = os.WriteFile(specPath, []byte(content), 0644)
+	err = os.WriteFile(r.SpecFilePath, []byte(content), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write spec.md: %w", err)
 	}
@@ -154,6 +223,19 @@ func (r *SpecRepo) Save(config *DocSpec) error {
 	return nil
 }
 
+// SpecMarkdownCurrent tells whether spec.md is what spec.json generates, it
+// is not when either was edited by hand
+func (r *SpecRepo) SpecMarkdownCurrent(config *DocSpec) (bool, error) {
+	content, err := os.ReadFile(r.SpecFilePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", r.SpecFilePath, err)
+	}
+	return string(content) == generateSpecContent(config), nil
+}
+
 func generateSpecContent(config *DocSpec) string {
 	var builder strings.Builder
 
@@ -172,6 +254,15 @@ func generateSpecContent(config *DocSpec) string {
 		}
 		builder.WriteString("\n")
 	}
+	generateConfluenceSpecContent(&builder, config.Confluence)
+
+	if len(config.PromptSources) > 0 {
+		builder.WriteString("## Prompt Sources\n\n")
+		for _, registry := range config.PromptSources {
+			builder.WriteString(fmt.Sprintf("- **%s:** `%s`\n", registry.Name, registry.Source))
+		}
+		builder.WriteString("\n")
+	}
 
 	// Documents section
 	builder.WriteString("## Documents\n\n")
@@ -194,6 +285,28 @@ func generateSpecContent(config *DocSpec) string {
 			} else {
 				builder.WriteString("*No file hints provided.*\n\n")
 			}
+
+			if doc.Parent != "" {
+				builder.WriteString(fmt.Sprintf("**Parent:** %s\n\n", describeParent(config, doc.Parent)))
+			}
+
+			if len(doc.Labels) > 0 {
+				labels := make([]string, 0, len(doc.Labels))
+				for _, label := range doc.Labels {
+					labels = append(labels, fmt.Sprintf("`%s`", label))
+				}
+				builder.WriteString(fmt.Sprintf("**Labels:** %s\n\n", strings.Join(labels, ", ")))
+			}
+
+			if len(doc.Properties) > 0 {
+				builder.WriteString("**Properties:**\n")
+				for _, key := range doc.PropertyKeys() {
+					builder.WriteString(fmt.Sprintf("- %s: %s\n", key, doc.Properties[key]))
+				}
+				builder.WriteString("\n")
+			}
+
+			generateTargetsContent(&builder, doc.Targets)
 		}
 	}
 
@@ -201,7 +314,7 @@ func generateSpecContent(config *DocSpec) string {
 }
 
 func (r *SpecRepo) loadJsonSpec() (*DocSpec, error) {
-
+	logger.Debug("Reading %s", r.SpecJsonFilePath)
 	content, err := os.ReadFile(r.SpecJsonFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read spec.json: %w", err)
@@ -219,6 +332,7 @@ func (r *SpecRepo) saveJsonSpec(spec *DocSpec) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal spec.json: %w", err)
 	}
+	logger.Debug("Writing %s with %d document(s)", r.SpecJsonFilePath, len(spec.DocMeta))
 	err = os.WriteFile(r.SpecJsonFilePath, content, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write spec.json: %w", err)
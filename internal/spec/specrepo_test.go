@@ -0,0 +1,34 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveWritesToSpecPaths(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join(t.TempDir(), "docs")
+	specRepo := &SpecRepo{
+		SpecFilePath:     filepath.Join(dir, "spec.md"),
+		SpecJsonFilePath: filepath.Join(dir, "spec.json"),
+	}
+	docSpec := &DocSpec{Platforms: []string{"confluence"}, DocMeta: []DocMetaData{{ID: "arch", Name: "Architecture"}}}
+	err := specRepo.Save(docSpec)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	for _, path := range []string{specRepo.SpecFilePath, specRepo.SpecJsonFilePath} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Save did not write %s: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(".docs"); !os.IsNotExist(err) {
+		t.Error("Save wrote to .docs in the working directory")
+	}
+	current, err := specRepo.SpecMarkdownCurrent(docSpec)
+	if err != nil || !current {
+		t.Errorf("SpecMarkdownCurrent = %v, %v after Save", current, err)
+	}
+}
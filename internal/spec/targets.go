@@ -0,0 +1,177 @@
+package spec
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Hasankanso/docli/internal/errs"
+)
+
+// DocTargets records where a document is published on each platform
+type DocTargets struct {
+	Confluence *ConfluenceTarget "json:\"confluence,omitempty\""
+	Readme     *ReadmeTarget     "json:\"readme,omitempty\""
+}
+
+// ConfluenceTarget maps a document to its Confluence page
+type ConfluenceTarget struct {
+	SpaceKey          string    "json:\"space_key,omitempty\""
+	ParentPageID      string    "json:\"parent_page_id,omitempty\""
+	PageID            string    "json:\"page_id,omitempty\""
+	LastSyncedVersion int       "json:\"last_synced_version,omitempty\""
+	LastSyncedHash    string    "json:\"last_synced_hash,omitempty\""
+	LastSyncedAt      time.Time "json:\"last_synced_at,omitzero\""
+	// Attachments maps the file names of uploaded attachments to the hash of
+	// their content
+	Attachments map[string]string "json:\"attachments,omitempty\""
+	// Labels are the labels docli added to the page, labels added on
+	// Confluence are left alone
+	Labels []string "json:\"labels,omitempty\""
+}
+
+// ConfluenceSpec holds the Confluence settings shared by all documents
+type ConfluenceSpec struct {
+	// RootPageTitle names a page created for the whole spec, documents without
+	// a parent are published under it
+	RootPageTitle string "json:\"root_page_title,omitempty\""
+	RootPageID    string "json:\"root_page_id,omitempty\""
+}
+
+// ReadmeTarget maps a document to its section in README.md
+type ReadmeTarget struct {
+	Anchor string "json:\"anchor,omitempty\""
+}
+
+// FindDocMeta returns the document with the given ID, or nil
+func (s *DocSpec) FindDocMeta(id string) *DocMetaData {
+	for i := range s.DocMeta {
+		if s.DocMeta[i].ID == id {
+			return &s.DocMeta[i]
+		}
+	}
+	return nil
+}
+
+// SetConfluenceRootPage records the root page created for the whole spec, an
+// empty title removes it
+func (r *SpecRepo) SetConfluenceRootPage(title, pageID string) error {
+	spec, err := r.loadJsonSpec()
+	if err != nil {
+		return err
+	}
+	spec.Confluence = nil
+	if title != "" {
+		spec.Confluence = &ConfluenceSpec{RootPageTitle: title, RootPageID: pageID}
+	}
+	return r.Save(spec)
+}
+
+func (r *SpecRepo) GetDocMeta(id string) (*DocMetaData, error) {
+	spec, err := r.loadJsonSpec()
+	if err != nil {
+		return nil, err
+	}
+	for i := range spec.DocMeta {
+		if spec.DocMeta[i].ID == id {
+			return &spec.DocMeta[i], nil
+		}
+	}
+	return nil, errs.NotFound("document's Meta data with ID '%s' not found", id)
+}
+
+// GetConfluenceTarget returns the Confluence mapping of a document, or nil
+// when the document has not been mapped yet
+func (r *SpecRepo) GetConfluenceTarget(id string) (*ConfluenceTarget, error) {
+	doc, err := r.GetDocMeta(id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Targets == nil {
+		return nil, nil
+	}
+	return doc.Targets.Confluence, nil
+}
+
+// SetConfluenceTarget replaces the Confluence mapping of a document, a nil
+// target removes it
+func (r *SpecRepo) SetConfluenceTarget(id string, target *ConfluenceTarget) error {
+	return r.UpdateDocMeta(id, func(doc *DocMetaData) {
+		if doc.Targets == nil {
+			doc.Targets = &DocTargets{}
+		}
+		doc.Targets.Confluence = target
+	})
+}
+
+// GetReadmeTarget returns the README mapping of a document, or nil when the
+// document has not been mapped yet
+func (r *SpecRepo) GetReadmeTarget(id string) (*ReadmeTarget, error) {
+	doc, err := r.GetDocMeta(id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Targets == nil {
+		return nil, nil
+	}
+	return doc.Targets.Readme, nil
+}
+
+// SetReadmeTarget replaces the README mapping of a document, a nil target
+// removes it
+func (r *SpecRepo) SetReadmeTarget(id string, target *ReadmeTarget) error {
+	return r.UpdateDocMeta(id, func(doc *DocMetaData) {
+		if doc.Targets == nil {
+			doc.Targets = &DocTargets{}
+		}
+		doc.Targets.Readme = target
+	})
+}
+
+func generateConfluenceSpecContent(builder *strings.Builder, confluence *ConfluenceSpec) {
+	if confluence == nil || confluence.RootPageTitle == "" {
+		return
+	}
+	builder.WriteString(fmt.Sprintf("**Confluence Root Page:** %s", confluence.RootPageTitle))
+	if confluence.RootPageID != "" {
+		builder.WriteString(fmt.Sprintf(" (page `%s`)", confluence.RootPageID))
+	}
+	builder.WriteString("\n\n")
+}
+
+// describeParent names the parent of a document, which is either another
+// document or a Confluence page
+func describeParent(spec *DocSpec, parent string) string {
+	if doc := spec.FindDocMeta(parent); doc != nil {
+		return fmt.Sprintf("%s (`%s`)", doc.Name, doc.ID)
+	}
+	return fmt.Sprintf("Confluence page `%s`", parent)
+}
+
+// generateTargetsContent lists where a document is published. The sync state
+// (versions, hashes, timestamps) stays in spec.json, so that spec.md only
+// changes when the mapping does.
+func generateTargetsContent(builder *strings.Builder, targets *DocTargets) {
+	if targets == nil || (targets.Confluence == nil && targets.Readme == nil) {
+		return
+	}
+
+	builder.WriteString("**Platform Mappings:**\n")
+	if confluence := targets.Confluence; confluence != nil {
+		var details []string
+		if confluence.SpaceKey != "" {
+			details = append(details, fmt.Sprintf("space `%s`", confluence.SpaceKey))
+		}
+		if confluence.PageID != "" {
+			details = append(details, fmt.Sprintf("page `%s`", confluence.PageID))
+		}
+		if confluence.ParentPageID != "" {
+			details = append(details, fmt.Sprintf("parent page `%s`", confluence.ParentPageID))
+		}
+		builder.WriteString(fmt.Sprintf("- Confluence: %s\n", strings.Join(details, ", ")))
+	}
+	if readme := targets.Readme; readme != nil {
+		builder.WriteString(fmt.Sprintf("- README: anchor `#%s`\n", readme.Anchor))
+	}
+	builder.WriteString("\n")
+}
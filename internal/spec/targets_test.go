@@ -0,0 +1,34 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpecContentLeavesOutSyncState(t *testing.T) {
+	target := &ConfluenceTarget{SpaceKey: "DOC", PageID: "1001", ParentPageID: "1000"}
+	docSpec := &DocSpec{
+		Platforms: []string{"confluence"},
+		DocMeta: []DocMetaData{{
+			ID:      "arch",
+			Name:    "Architecture",
+			Targets: &DocTargets{Confluence: target},
+		}},
+	}
+	before := generateSpecContent(docSpec)
+	for _, want := range []string{"space `DOC`", "page `1001`", "parent page `1000`"} {
+		if !strings.Contains(before, want) {
+			t.Errorf("spec.md does not mention %s:\n%s", want, before)
+		}
+	}
+
+	// A sync only changes the sync state, which must not touch spec.md
+	target.LastSyncedVersion = 7
+	target.LastSyncedHash = "c0ffee"
+	target.LastSyncedAt = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	target.Attachments = map[string]string{"arch.png": "beef"}
+	if after := generateSpecContent(docSpec); after != before {
+		t.Errorf("spec.md changed with the sync state:\n--- before ---\n%s\n--- after ---\n%s", before, after)
+	}
+}
@@ -1,9 +1,23 @@
 package main
 
 import (
+	"embed"
+	"io/fs"
+
 	"github.com/Hasankanso/docli/cmd"
+	"github.com/Hasankanso/docli/internal/prompts"
 )
 
+// promptFiles are the prompt files 'docli init' copies by default, embedded
+// so that init works without network access
+//
+//go:embed .github/prompts/*.prompt.md
+var promptFiles embed.FS
+
 func main() {
+	embedded, err := fs.Sub(promptFiles, ".github/prompts")
+	if err == nil {
+		prompts.Embedded = embedded
+	}
 	cmd.Execute()
 }